@@ -14,12 +14,15 @@ import (
 /*
 TaskToSendToSolver описывает структуру задачи,
 которая будет отправлена вычислителю, если
-он задачу запросит. Включает в себя само выражение
-и словарь со временем выполнения для операций
+он задачу запросит. Включает в себя само выражение,
+значения его переменных, словарь со временем выполнения для операций
+и номер задачи, который нужно вернуть вместе с ответом
 */
 type TaskToSendToSolver struct {
-	Expression string         `json:"expression"`
-	Times      map[string]int `json:"times"`
+	Expression string             `json:"expression"`
+	Variables  map[string]float64 `json:"variables"`
+	Times      map[string]int     `json:"times"`
+	TaskID     int                `json:"taskId"`
 }
 
 /*
@@ -28,13 +31,15 @@ ResultFromSolver описывает JSON с результатом
 иметь вычислитель, желающий отправить ответ.
 Включает в себя выражение, ответ и сообщение с
 ошибками, комментарием от вычислителя и т. п.
-используется в исполнителе SetResultOfSolving
+используется в исполнителе SetResultOfSolving.
+Ответ записывается в задачу с номером TaskID
 */
 type ResultFromSolver struct {
 	SolverName string `json:"solverName"`
 	Expression string `json:"expression"`
 	Result     string `json:"result"`
 	Status     int    `json:"status"`
+	TaskID     int    `json:"taskId"`
 }
 
 /*
@@ -127,12 +132,21 @@ func (as *AbsoluleSolver) RunSolverStream() {
 			// Переменная для отклика
 			var resp *http.Response
 			for {
-				// Пробуем отправить запрос на получение задачи
+				// Пробуем отправить запрос на получение задачи. Оркестратор
+				// держит запрос, пока задача не появится
 				resp, err = http.Post("http://orchestrator_server:8082/getTaskToSolving", "application/json", bytes.NewBuffer(jsonRequest))
+				if err == nil && resp.StatusCode == http.StatusNoContent {
+					// Задача за время ожидания не появилась, спрашиваем снова
+					resp.Body.Close()
+					continue
+				}
 				if err != nil || resp.StatusCode != http.StatusOK {
 					// Если не удалочь отправить успешный запрос,
 					// то ждем две секунды, и пытаемся отправить запрос повторно
 					log.Println("[ERROR]: Can not connect to orkestrator")
+					if err == nil {
+						resp.Body.Close()
+					}
 					time.Sleep(2 * time.Second)
 
 				} else {
@@ -162,8 +176,12 @@ func (as *AbsoluleSolver) RunSolverStream() {
 				panic(err)
 			}
 
-			// Вчисляем выражение
-			res, err := evaluateMathExpression(expr, make(map[string]interface{}))
+			// Вчисляем выражение, подставляя значения переменных
+			vars := make(map[string]interface{})
+			for name, value := range message.Variables {
+				vars[name] = value
+			}
+			res, err := evaluateMathExpression(expr, vars)
 			if err != nil {
 				log.Println("[ERROR]: Decoding JSON was failed: " + err.Error())
 				panic(err)
@@ -179,6 +197,7 @@ func (as *AbsoluleSolver) RunSolverStream() {
 				Expression: message.Expression,
 				Result:     fmt.Sprintf("%v", res),
 				Status:     0,
+				TaskID:     message.TaskID,
 			}
 
 			// Формируем JSON
@@ -0,0 +1,162 @@
+package expression
+
+import (
+	"container/heap"
+	"time"
+)
+
+/*
+Balance перестраивает цепочки одинаковых ассоциативных
+операций (+ и *) так, чтобы их можно было считать параллельно.
+Например a+b+c+d, которое парсер строит как ((a+b)+c)+d,
+превращается в (a+b)+(c+d). Операнды цепочки объединяются
+попарно, начиная с тех, что будут готовы раньше всех
+
+Parameters:
+
+	*Node: Корень дерева выражения
+	map[string]int: Время выполнения операций в секундах
+
+Returns:
+
+	*Node: Корень перестроенного дерева
+*/
+func Balance(node *Node, times map[string]int) *Node {
+	if node.IsLeaf() {
+		return node
+	}
+
+	if node.IsFunction() {
+		arguments := make([]*Node, 0, len(node.Arguments))
+		for _, argument := range node.Arguments {
+			arguments = append(arguments, Balance(argument, times))
+		}
+		return &Node{Operation: node.Operation, Position: node.Position, Arguments: arguments}
+	}
+
+	if node.Operation != "+" && node.Operation != "*" {
+		return &Node{
+			Operation: node.Operation,
+			Left:      Balance(node.Left, times),
+			Right:     Balance(node.Right, times),
+		}
+	}
+
+	// Собираем все операнды цепочки одной и той же операции
+	operands := make([]*Node, 0)
+	var collect func(n *Node)
+	collect = func(n *Node) {
+		if !n.IsLeaf() && !n.IsFunction() && n.Operation == node.Operation {
+			collect(n.Left)
+			collect(n.Right)
+			return
+		}
+		operands = append(operands, Balance(n, times))
+	}
+	collect(node)
+
+	// Каждый раз объединяем два операнда, которые будут готовы раньше
+	// остальных, так самая долгая ветка попадает ближе всего к корню.
+	// Время готовности операнда считается один раз, а у объединенного
+	// операнда это время самого долгого из двух плюс время операции
+	queue := make(operandQueue, 0, len(operands))
+	for _, operand := range operands {
+		queue = append(queue, queuedOperand{node: operand, path: CriticalPath(operand, times), order: len(queue)})
+	}
+	heap.Init(&queue)
+
+	duration := OperationTime(times, node.Operation)
+	for order := len(queue); queue.Len() > 1; order++ {
+		first := heap.Pop(&queue).(queuedOperand)
+		second := heap.Pop(&queue).(queuedOperand)
+		// Второй операнд готов не раньше первого
+		heap.Push(&queue, queuedOperand{
+			node:  &Node{Operation: node.Operation, Left: first.node, Right: second.node},
+			path:  second.path + duration,
+			order: order,
+		})
+	}
+
+	return queue[0].node
+}
+
+/*
+queuedOperand описывает операнд цепочки в очереди на объединение:
+время его готовности и порядок появления в очереди, по которому
+операнды с одинаковым временем объединяются слева направо
+*/
+type queuedOperand struct {
+	node  *Node
+	path  time.Duration
+	order int
+}
+
+/*
+operandQueue это очередь операндов с приоритетом, первым
+из нее берется операнд, который будет готов раньше остальных
+*/
+type operandQueue []queuedOperand
+
+func (q operandQueue) Len() int { return len(q) }
+
+func (q operandQueue) Less(i, j int) bool {
+	if q[i].path != q[j].path {
+		return q[i].path < q[j].path
+	}
+	return q[i].order < q[j].order
+}
+
+func (q operandQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *operandQueue) Push(x any) { *q = append(*q, x.(queuedOperand)) }
+
+func (q *operandQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+/*
+CriticalPath возвращает время, через которое будет посчитано
+дерево при параллельном вычислении независимых поддеревьев
+*/
+func CriticalPath(node *Node, times map[string]int) time.Duration {
+	if node.IsLeaf() {
+		return 0
+	}
+
+	var longest time.Duration
+	for _, operand := range node.Operands() {
+		if path := CriticalPath(operand, times); path > longest {
+			longest = path
+		}
+	}
+
+	return longest + OperationTime(times, node.Operation)
+}
+
+/*
+Estimate предсказывает время вычисления выражения так, как его
+посчитает планировщик: дерево разбирается, цепочки ассоциативных
+операций перестраиваются, после чего находится время самого
+долгого пути от числа до корня дерева
+
+Parameters:
+
+	string: Входное выражение в строке
+	map[string]int: Время выполнения операций в секундах
+
+Returns:
+
+	time.Duration: Предсказанное время вычисления
+	error: Ошибки разбора выражения
+*/
+func Estimate(expression string, times map[string]int) (time.Duration, error) {
+	tree, err := Parse(expression)
+	if err != nil {
+		return 0, err
+	}
+
+	return CriticalPath(Balance(tree, times), times), nil
+}
@@ -0,0 +1,90 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBalance(t *testing.T) {
+	times := map[string]int{"+": 1, "-": 1, "*": 2, "/": 1}
+	tests := []struct {
+		expression string
+		tree       string
+	}{
+		{"1+2+3+4", "((1+2)+(3+4))"},
+		{"1*2*3*4", "((1*2)*(3*4))"},
+		{"1+2*3*4*5", "(1+((2*3)*(4*5)))"},
+		{"1*2+3*4+5", "((3*4)+(5+(1*2)))"},
+		{"1-2-3-4", "(((1-2)-3)-4)"},
+		{"(1+2)*(3+4)", "((1+2)*(3+4))"},
+		{"5", "5"},
+	}
+
+	for _, test := range tests {
+		tree, err := Parse(test.expression)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		if balanced := Balance(tree, times).String(); balanced != test.tree {
+			t.Errorf("Balance(%q) = %v, want %v", test.expression, balanced, test.tree)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	times := map[string]int{"+": 1, "-": 1, "*": 2, "/": 1}
+	tests := []struct {
+		expression string
+		estimate   time.Duration
+		path       time.Duration
+	}{
+		{"1+2+3+4", 2 * time.Second, 3 * time.Second},
+		{"1+2*3*4*5", 5 * time.Second, 7 * time.Second},
+		{"(1+2)*(3+4)", 3 * time.Second, 3 * time.Second},
+		{"1-2-3-4", 3 * time.Second, 3 * time.Second},
+		{"5", 0, 0},
+	}
+
+	for _, test := range tests {
+		estimate, err := Estimate(test.expression, times)
+		if err != nil || estimate != test.estimate {
+			t.Errorf("Estimate(%q) = %v, %v, want %v", test.expression, estimate, err, test.estimate)
+		}
+		// Без перестройки дерева цепочка считается последовательно
+		tree, _ := Parse(test.expression)
+		if path := CriticalPath(tree, times); path != test.path {
+			t.Errorf("CriticalPath(%q) = %v, want %v", test.expression, path, test.path)
+		}
+	}
+
+	if _, err := Estimate("1+", times); err == nil {
+		t.Errorf("Estimate(%q) returned no error", "1+")
+	}
+}
+
+func TestBalanceLongChain(t *testing.T) {
+	times := map[string]int{"+": 1, "-": 1, "*": 2, "/": 1}
+	tests := []struct {
+		operands int
+		path     time.Duration
+	}{
+		{1024, 10 * time.Second},
+		{4096, 12 * time.Second},
+		{5000, 13 * time.Second},
+	}
+
+	for _, test := range tests {
+		expression := strings.Repeat("1+", test.operands-1) + "1"
+		start := time.Now()
+		estimate, err := Estimate(expression, times)
+		if err != nil || estimate != test.path {
+			t.Errorf("Estimate of %v operands = %v, %v, want %v", test.operands, estimate, err, test.path)
+		}
+		// Цепочка перестраивается за время, близкое к линейному
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Estimate of %v operands took %v", test.operands, elapsed)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package expression
+
+import (
+	"fmt"
+)
+
+/*
+SyntaxError описывает ошибку разбора выражения:
+позицию (номер байта от начала строки), на которой
+возникла ошибка, лексему, которая оказалась на этой
+позиции, и понятное человеку описание причины
+*/
+type SyntaxError struct {
+	Position int
+	Token    string
+	Reason   string
+}
+
+/*
+Column возвращает номер колонки ошибки, считая с единицы
+*/
+func (e *SyntaxError) Column() int {
+	return e.Position + 1
+}
+
+/*
+Error возвращает описание ошибки, например
+unexpected ')' at column 7
+*/
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v at column %v", e.Reason, e.Column())
+}
+
+/*
+unknownVariable создает ошибку о переменной,
+значение которой не передано вместе с выражением
+*/
+func unknownVariable(node *Node) *SyntaxError {
+	return &SyntaxError{
+		Position: node.Position,
+		Token:    node.Variable,
+		Reason:   fmt.Sprintf("unknown variable '%v'", node.Variable),
+	}
+}
+
+/*
+unexpectedToken создает ошибку о лексеме, которой
+не должно быть на этом месте выражения
+*/
+func unexpectedToken(token Token) *SyntaxError {
+	if token.Type == EndToken {
+		return &SyntaxError{
+			Position: token.Position,
+			Token:    "",
+			Reason:   "unexpected end of expression",
+		}
+	}
+
+	return &SyntaxError{
+		Position: token.Position,
+		Token:    token.Value,
+		Reason:   fmt.Sprintf("unexpected '%v'", token.Value),
+	}
+}
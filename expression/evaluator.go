@@ -0,0 +1,258 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+Calculator описывает функцию, выполняющую одну операцию
+или функцию дерева выражения. Вычислитель сам решает, как
+именно ее выполнять, например с задержкой по времени
+*/
+type Calculator func(operation string, args []Number) (Number, error)
+
+/*
+operationState описывает состояние одной операции
+дерева во время работы планировщика: сколько операндов
+еще не посчитано, на какой операции ждут ее результат
+и каким по счету операндом этой операции она является
+*/
+type operationState struct {
+	node    *Node
+	parent  *operationState
+	index   int
+	pending int
+	args    []Number
+}
+
+/*
+operationResult описывает результат выполнения операции,
+который горутина отправляет обратно планировщику
+*/
+type operationResult struct {
+	state *operationState
+	value Number
+	err   error
+}
+
+/*
+Schedule вычисляет дерево выражения, запуская каждую операцию
+в отдельной горутине, как только готовы все ее операнды.
+Независимые поддеревья считаются одновременно, поэтому
+общее время вычисления равно самому долгому пути
+от листа до корня дерева
+
+Parameters:
+
+	*Node: Корень дерева выражения
+	string: Режим вычисления (FloatMode или RationalMode)
+	Calculator: Функция, выполняющая одну операцию
+
+Returns:
+
+	Number: Результат вычисления
+	error: Первая ошибка, возникшая при вычислении
+*/
+func Schedule(root *Node, mode string, calculate Calculator) (Number, error) {
+	if root.IsLeaf() {
+		return root.Number(mode)
+	}
+
+	// Строим состояния для всех операций дерева
+	// и находим те, у которых все операнды числа
+	states := make([]*operationState, 0)
+	var build func(node *Node, parent *operationState, index int) error
+	build = func(node *Node, parent *operationState, index int) error {
+		if node.IsLeaf() {
+			return nil
+		}
+		operands := node.Operands()
+		state := &operationState{node: node, parent: parent, index: index, args: make([]Number, len(operands))}
+		states = append(states, state)
+		for i, operand := range operands {
+			if !operand.IsLeaf() {
+				state.pending += 1
+				if err := build(operand, state, i); err != nil {
+					return err
+				}
+				continue
+			}
+			value, err := operand.Number(mode)
+			if err != nil {
+				return err
+			}
+			state.args[i] = value
+		}
+		return nil
+	}
+	if err := build(root, nil, 0); err != nil {
+		return Number{}, err
+	}
+
+	// Канал с результатами буферизирован на все операции, чтобы
+	// горутины не зависали, если планировщик завершился с ошибкой
+	results := make(chan operationResult, len(states))
+	run := func(state *operationState) {
+		go func() {
+			value, err := calculate(state.node.Operation, state.args)
+			results <- operationResult{state: state, value: value, err: err}
+		}()
+	}
+
+	running := 0
+	for _, state := range states {
+		if state.pending == 0 {
+			run(state)
+			running += 1
+		}
+	}
+
+	for running > 0 {
+		result := <-results
+		running -= 1
+
+		if result.err != nil {
+			return Number{}, result.err
+		}
+
+		parent := result.state.parent
+		if parent == nil {
+			return result.value, nil
+		}
+
+		// Передаем результат операции, которая его ждет,
+		// и запускаем ее, если готовы все операнды
+		parent.args[result.state.index] = result.value
+		parent.pending -= 1
+		if parent.pending == 0 {
+			run(parent)
+			running += 1
+		}
+	}
+
+	return Number{}, nil
+}
+
+/*
+Evaluate вычисляет выражение: разбирает его, подставляет значения
+переменных, перестраивает цепочки ассоциативных операций и запускает
+планировщик, выдерживая время выполнения каждой операции
+
+Parameters:
+
+	string: Входное выражение в строке
+	map[string]float64: Значения переменных выражения
+	map[string]int: Время выполнения операций в секундах
+	string: Режим вычисления (FloatMode или RationalMode)
+
+Returns:
+
+	Number: Результат вычисления
+	error: Ошибки разбора и вычисления
+*/
+func Evaluate(expression string, variables map[string]float64, times map[string]int, mode string) (Number, error) {
+	return EvaluateWith(expression, variables, times, mode, TimedCalculator(times))
+}
+
+/*
+EvaluateWith работает так же, как Evaluate, но каждую операцию
+выполняет переданной функцией calculate, например что бы перед
+выполнением операции поискать ее результат в общем хранилище
+
+Parameters:
+
+	string: Входное выражение в строке
+	map[string]float64: Значения переменных выражения
+	map[string]int: Время выполнения операций в секундах
+	string: Режим вычисления (FloatMode или RationalMode)
+	Calculator: Функция, выполняющая одну операцию
+
+Returns:
+
+	Number: Результат вычисления
+	error: Ошибки разбора и вычисления
+*/
+func EvaluateWith(expression string, variables map[string]float64, times map[string]int,
+	mode string, calculate Calculator) (Number, error) {
+	tree, err := ParseWithVariables(expression, variables)
+	if err != nil {
+		return Number{}, err
+	}
+
+	return Schedule(Balance(tree, times), mode, calculate)
+}
+
+/*
+OperationKey возвращает ключ операции с уже посчитанными
+операндами, например rational:*(12,17). Одинаковые операции
+с одинаковыми операндами в одном режиме вычисления имеют
+одинаковый ключ, по нему результаты подвыражений разных
+задач хранятся в общем хранилище
+*/
+func OperationKey(operation string, args []Number, mode string) string {
+	if mode == "" {
+		mode = FloatMode
+	}
+	texts := make([]string, 0, len(args))
+	for _, arg := range args {
+		texts = append(texts, arg.String())
+	}
+	return mode + ":" + operation + "(" + strings.Join(texts, ",") + ")"
+}
+
+/*
+TimedCalculator возвращает функцию, которая выполняет операцию
+и выдерживает время ее выполнения из словаря times
+*/
+func TimedCalculator(times map[string]int) Calculator {
+	return func(operation string, args []Number) (Number, error) {
+		res, err := Apply(operation, args...)
+		if err != nil {
+			return Number{}, err
+		}
+
+		time.Sleep(OperationTime(times, operation))
+		return res, nil
+	}
+}
+
+/*
+WithContext оборачивает функцию, выполняющую операцию, так, что
+перед каждой операцией проверяется контекст. Если вычисление
+отменено, то следующая операция не запускается, а возвращается
+ошибка контекста, и планировщик завершает вычисление
+*/
+func WithContext(ctx context.Context, calculate Calculator) Calculator {
+	return func(operation string, args []Number) (Number, error) {
+		if err := ctx.Err(); err != nil {
+			return Number{}, err
+		}
+		return calculate(operation, args)
+	}
+}
+
+/*
+Apply выполняет одну операцию или функцию из реестра
+операций без задержки. Если все операнды точные дроби,
+то и результат будет точной дробью
+
+Parameters:
+
+	string: Знак операции или имя функции
+	...Number: Операнды, для операции ровно два
+
+Returns:
+
+	Number: Результат операции
+	error: Ошибки вычисления, например деление на ноль
+*/
+func Apply(operation string, args ...Number) (Number, error) {
+	registered, ok := Lookup(operation)
+	if !ok {
+		return Number{}, fmt.Errorf("Unknown operation: %v", operation)
+	}
+	return registered.call(args)
+}
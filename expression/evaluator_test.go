@@ -0,0 +1,144 @@
+package expression
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+/*
+zeroTimes возвращает время выполнения всех операций, равное нулю,
+что бы тесты не ждали время операций по умолчанию
+*/
+func zeroTimes() map[string]int {
+	times := make(map[string]int)
+	for _, name := range Operations() {
+		times[name] = 0
+	}
+	return times
+}
+
+func TestEvaluate(t *testing.T) {
+	variables := map[string]float64{"x": 2, "rate_2": 0.5}
+	tests := []struct {
+		expression string
+		float      string
+		rational   string
+	}{
+		{"2+2*2", "6", "6"},
+		{"(1+2)*3", "9", "9"},
+		{"1-2-3", "-4", "-4"},
+		{"2^3^2", "512", "512"},
+		{"-2^2", "-4", "-4"},
+		{"2*-1", "-2", "-2"},
+		{"-(1+2)", "-3", "-3"},
+		{"7//2", "3", "3"},
+		{"-7//2", "-4", "-4"},
+		{"-7%2", "1", "1"},
+		{"7%-2", "-1", "-1"},
+		{"1/3+1/3+1/3", "1", "1"},
+		{"0.1+0.2", "0.30000000000000004", "3/10"},
+		{"1/3", "0.3333333333333333", "1/3"},
+		{"1e3+.5", "1000.5", "2001/2"},
+		{"2^-1", "0.5", "1/2"},
+		{"sqrt(9/4)", "1.5", "3/2"},
+		{"abs(-5)+min(3,1,2)+max(1,4)", "10", "10"},
+		{"round(7/2)", "4", "4"},
+		{"round(1.5, 400)", "1.5", "3/2"},
+		{"round(1.5, -400)", "0", "0"},
+		{"x*x+rate_2", "4.5", "9/2"},
+		{"-x", "-2", "-2"},
+	}
+
+	times := zeroTimes()
+	for _, test := range tests {
+		float, err := Evaluate(test.expression, variables, times, FloatMode)
+		if err != nil || float.String() != test.float {
+			t.Errorf("Evaluate(%q, float) = %v, %v, want %v", test.expression, float.String(), err, test.float)
+		}
+		rational, err := Evaluate(test.expression, variables, times, RationalMode)
+		if err != nil || rational.String() != test.rational {
+			t.Errorf("Evaluate(%q, rational) = %v, %v, want %v", test.expression, rational.String(), err, test.rational)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		mode       string
+	}{
+		{"1/0", FloatMode},
+		{"1/0", RationalMode},
+		{"10//0", FloatMode},
+		{"5%0", RationalMode},
+		{"1/(2-2)", FloatMode},
+		{"sqrt(-1)", FloatMode},
+		{"y+1", FloatMode},
+		{"1e308*10", FloatMode},
+		{"1e308/1e-10", FloatMode},
+		{"1e308+1e308", FloatMode},
+		{"1e308-(-1e308)", FloatMode},
+	}
+
+	times := zeroTimes()
+	for _, test := range tests {
+		if res, err := Evaluate(test.expression, nil, times, test.mode); err == nil {
+			t.Errorf("Evaluate(%q, %v) = %v, want error", test.expression, test.mode, res.String())
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		expression string
+		precision  int
+		decimal    string
+	}{
+		{"1/3", 5, "0.33333"},
+		{"2/3", 2, "0.67"},
+		{"5", 3, "5.000"},
+	}
+
+	times := zeroTimes()
+	for _, test := range tests {
+		res, err := Evaluate(test.expression, nil, times, RationalMode)
+		if err != nil || res.Decimal(test.precision) != test.decimal {
+			t.Errorf("Decimal(%q, %v) = %v, %v, want %v",
+				test.expression, test.precision, res.Decimal(test.precision), err, test.decimal)
+		}
+	}
+}
+
+func TestWithContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := EvaluateWith("1+2", nil, zeroTimes(), FloatMode,
+		WithContext(ctx, TimedCalculator(zeroTimes())))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("EvaluateWith with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestExactSizeLimit(t *testing.T) {
+	tests := []struct {
+		expression string
+		ok         bool
+	}{
+		{"2^1024", true},
+		{"10^-400", true},
+		{"(2^1024)^1024", false},
+		{"pow(pow(2, 1000), 1000)", false},
+		{"2^8000*2^8000", false},
+		{"1e100000", false},
+	}
+
+	times := zeroTimes()
+	for _, test := range tests {
+		_, err := Evaluate(test.expression, nil, times, RationalMode)
+		if (err == nil) != test.ok {
+			t.Errorf("Evaluate(%q, rational) error = %v, want ok %v", test.expression, err, test.ok)
+		}
+	}
+}
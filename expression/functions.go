@@ -0,0 +1,224 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+/*
+maxExactExponent наибольшая по модулю степень, в которую
+дробь возводится точно, большие степени считаются через float64
+*/
+const maxExactExponent = 1024
+
+/*
+builtinFunctions содержит встроенные функции выражения,
+при запуске они записываются в реестр операций
+*/
+var builtinFunctions = []Operation{
+	{
+		Symbol: "sqrt", Kind: FunctionOperation, MinArgs: 1, MaxArgs: 1,
+		Float: func(args []float64) (float64, error) {
+			if args[0] < 0 {
+				return 0, fmt.Errorf("Square root of negative number: sqrt(%v)", args[0])
+			}
+			return math.Sqrt(args[0]), nil
+		},
+		Rational: ratSqrt,
+	},
+	{
+		Symbol: "abs", Kind: FunctionOperation, MinArgs: 1, MaxArgs: 1,
+		Float: func(args []float64) (float64, error) {
+			return math.Abs(args[0]), nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Abs(args[0]), nil
+		},
+	},
+	{
+		Symbol: "pow", Kind: FunctionOperation, MinArgs: 2, MaxArgs: 2,
+		Float:    floatPow,
+		Rational: ratPow,
+	},
+	{
+		Symbol: "min", Kind: FunctionOperation, MinArgs: 1, MaxArgs: -1,
+		Float: func(args []float64) (float64, error) {
+			res := args[0]
+			for _, arg := range args[1:] {
+				res = math.Min(res, arg)
+			}
+			return res, nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			res := args[0]
+			for _, arg := range args[1:] {
+				if arg.Cmp(res) < 0 {
+					res = arg
+				}
+			}
+			return new(big.Rat).Set(res), nil
+		},
+	},
+	{
+		Symbol: "max", Kind: FunctionOperation, MinArgs: 1, MaxArgs: -1,
+		Float: func(args []float64) (float64, error) {
+			res := args[0]
+			for _, arg := range args[1:] {
+				res = math.Max(res, arg)
+			}
+			return res, nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			res := args[0]
+			for _, arg := range args[1:] {
+				if arg.Cmp(res) > 0 {
+					res = arg
+				}
+			}
+			return new(big.Rat).Set(res), nil
+		},
+	},
+	{
+		Symbol: "round", Kind: FunctionOperation, MinArgs: 1, MaxArgs: 2,
+		Float: func(args []float64) (float64, error) {
+			digits := 0.0
+			if len(args) == 2 {
+				digits = args[1]
+			}
+			if digits != math.Trunc(digits) {
+				return 0, fmt.Errorf("Number of digits must be integer: round(%v, %v)", args[0], digits)
+			}
+			// Если знаков больше, чем хранит float64, то число уже
+			// округлено, а если сильно меньше, то округляется до нуля
+			scale := math.Pow(10, digits)
+			scaled := args[0] * scale
+			if math.IsInf(scale, 0) || math.IsInf(scaled, 0) {
+				return args[0], nil
+			}
+			if scale == 0 {
+				return 0, nil
+			}
+			return math.Round(scaled) / scale, nil
+		},
+		Rational: ratRound,
+	},
+}
+
+/*
+floatPow возводит число в степень, результат
+должен быть конечным числом
+*/
+func floatPow(args []float64) (float64, error) {
+	res := math.Pow(args[0], args[1])
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return 0, fmt.Errorf("Result is not a finite number: pow(%v, %v)", args[0], args[1])
+	}
+	return res, nil
+}
+
+/*
+ratSqrt извлекает корень из дроби. Если числитель и знаменатель
+являются точными квадратами, то корень точный, иначе он
+считается через float64
+*/
+func ratSqrt(args []*big.Rat) (*big.Rat, error) {
+	if args[0].Sign() < 0 {
+		return nil, fmt.Errorf("Square root of negative number: sqrt(%v)", args[0].RatString())
+	}
+
+	num := new(big.Int).Sqrt(args[0].Num())
+	den := new(big.Int).Sqrt(args[0].Denom())
+	if new(big.Int).Mul(num, num).Cmp(args[0].Num()) == 0 &&
+		new(big.Int).Mul(den, den).Cmp(args[0].Denom()) == 0 {
+		return new(big.Rat).SetFrac(num, den), nil
+	}
+
+	value, _ := args[0].Float64()
+	return new(big.Rat).SetFloat64(math.Sqrt(value)), nil
+}
+
+/*
+ratPow возводит дробь в степень. Целая степень считается точно,
+дробная через float64. Если точный результат больше maxExactBits,
+то возвращается ошибка
+*/
+func ratPow(args []*big.Rat) (*big.Rat, error) {
+	base, exponent := args[0], args[1]
+	if !exponent.IsInt() || !exponent.Num().IsInt64() ||
+		absInt64(exponent.Num().Int64()) > maxExactExponent {
+		x, _ := base.Float64()
+		y, _ := exponent.Float64()
+		res := math.Pow(x, y)
+		if math.IsNaN(res) || math.IsInf(res, 0) {
+			return nil, fmt.Errorf("Result is not a finite number: pow(%v, %v)", base.RatString(), exponent.RatString())
+		}
+		return new(big.Rat).SetFloat64(res), nil
+	}
+
+	n := exponent.Num().Int64()
+	if n < 0 && base.Sign() == 0 {
+		return nil, fmt.Errorf("Division by zero: pow(%v, %v)", base.RatString(), n)
+	}
+	if n < 0 {
+		n = -n
+		base = new(big.Rat).Inv(base)
+	}
+
+	// Размер степени растет линейно от показателя, поэтому слишком
+	// большую дробь отбрасываем до того, как ее посчитать
+	bits := int64(base.Num().BitLen() + base.Denom().BitLen() - 2)
+	if bits*n > maxExactBits {
+		return nil, fmt.Errorf("Exact number is too large: pow(%v, %v)", base.RatString(), exponent.RatString())
+	}
+
+	power := big.NewInt(n)
+	num := new(big.Int).Exp(base.Num(), power, nil)
+	den := new(big.Int).Exp(base.Denom(), power, nil)
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+/*
+ratRound округляет дробь до нужного количества знаков
+после запятой, половина округляется от нуля
+*/
+func ratRound(args []*big.Rat) (*big.Rat, error) {
+	digits := big.NewRat(0, 1)
+	if len(args) == 2 {
+		digits = args[1]
+	}
+	if !digits.IsInt() || !digits.Num().IsInt64() {
+		return nil, fmt.Errorf("Number of digits must be integer: round(%v, %v)", args[0].RatString(), digits.RatString())
+	}
+
+	n := digits.Num().Int64()
+	if absInt64(n) > maxExactExponent {
+		return nil, fmt.Errorf("Number of digits is too large: round(%v, %v)", args[0].RatString(), n)
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(absInt64(n)), nil))
+	if n < 0 {
+		scale.Inv(scale)
+	}
+
+	// Сдвигаем запятую, округляем до целого и сдвигаем обратно
+	scaled := new(big.Rat).Mul(args[0], scale)
+	half := big.NewRat(1, 2)
+	if scaled.Sign() < 0 {
+		scaled.Sub(scaled, half)
+	} else {
+		scaled.Add(scaled, half)
+	}
+	rounded := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+
+	return new(big.Rat).Quo(new(big.Rat).SetInt(rounded), scale), nil
+}
+
+/*
+absInt64 возвращает модуль целого числа
+*/
+func absInt64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,3 @@
+module expression
+
+go 1.20
@@ -0,0 +1,190 @@
+package expression
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+TokenType описывает тип лексемы арифметического выражения
+*/
+type TokenType int
+
+const (
+	NumberToken TokenType = iota
+	IdentifierToken
+	OperatorToken
+	LeftBracketToken
+	RightBracketToken
+	CommaToken
+	EndToken
+)
+
+/*
+Token описывает одну лексему выражения: ее тип,
+текстовое значение и позицию (номер байта) в исходной строке
+*/
+type Token struct {
+	Type     TokenType
+	Value    string
+	Position int
+}
+
+/*
+Tokenize разбивает строку с выражением на лексемы.
+Пробелы между лексемами пропускаются, в конец
+списка всегда добавляется лексема EndToken.
+Неизвестный символ возвращается как *SyntaxError
+
+Parameters:
+
+	string: Входное выражение в строке
+
+Returns:
+
+	[]Token: Список лексем
+	error: Ошибки
+*/
+func Tokenize(expression string) ([]Token, error) {
+	tokens := make([]Token, 0)
+
+	for i := 0; i < len(expression); {
+		// Символ декодируем целиком, что бы не разбирать
+		// по отдельности байты символа не из ASCII
+		ch, size := utf8.DecodeRuneInString(expression[i:])
+
+		switch {
+		case unicode.IsSpace(ch):
+			i += size
+
+		case isDigit(ch) || ch == '.':
+			// Число читаем целиком, вместе с дробной частью и порядком
+			begin := i
+			i = scanNumber(expression, i)
+			tokens = append(tokens, Token{Type: NumberToken, Value: expression[begin:i], Position: begin})
+
+		case isLetter(ch) || ch == '_':
+			// Имя переменной, значение которой передается вместе с задачей
+			begin := i
+			i = scanIdentifier(expression, i)
+			tokens = append(tokens, Token{Type: IdentifierToken, Value: expression[begin:i], Position: begin})
+
+		case matchOperator(expression, i) != "":
+			// Знак операции может быть длиннее одного символа, например //
+			symbol := matchOperator(expression, i)
+			tokens = append(tokens, Token{Type: OperatorToken, Value: symbol, Position: i})
+			i += len(symbol)
+
+		case ch == '(':
+			tokens = append(tokens, Token{Type: LeftBracketToken, Value: "(", Position: i})
+			i += 1
+
+		case ch == ')':
+			tokens = append(tokens, Token{Type: RightBracketToken, Value: ")", Position: i})
+			i += 1
+
+		case ch == ',':
+			tokens = append(tokens, Token{Type: CommaToken, Value: ",", Position: i})
+			i += 1
+
+		default:
+			return nil, &SyntaxError{
+				Position: i,
+				Token:    string(ch),
+				Reason:   fmt.Sprintf("unexpected symbol '%c'", ch),
+			}
+		}
+	}
+
+	tokens = append(tokens, Token{Type: EndToken, Value: "", Position: len(expression)})
+	return tokens, nil
+}
+
+/*
+scanIdentifier находит конец имени переменной, начинающегося
+с позиции begin. Имя состоит из латинских букв, цифр и знака
+подчеркивания и не может начинаться с цифры, например a, x1, rate_2
+
+Parameters:
+
+	string: Входное выражение в строке
+	int: Позиция начала имени
+
+Returns:
+
+	int: Позиция сразу после конца имени
+*/
+func scanIdentifier(expression string, begin int) int {
+	i := begin
+	for i < len(expression) {
+		ch := rune(expression[i])
+		if !isLetter(ch) && !isDigit(ch) && ch != '_' {
+			break
+		}
+		i += 1
+	}
+	return i
+}
+
+/*
+scanNumber находит конец числа, начинающегося с позиции begin.
+Число может содержать дробную часть и порядок в научной
+записи, например 12, 0.5, .5, 1e3, 2.5E-4
+
+Parameters:
+
+	string: Входное выражение в строке
+	int: Позиция начала числа
+
+Returns:
+
+	int: Позиция сразу после конца числа
+*/
+func scanNumber(expression string, begin int) int {
+	i := begin
+	for i < len(expression) && isDigit(rune(expression[i])) {
+		i += 1
+	}
+
+	// Дробная часть
+	if i < len(expression) && expression[i] == '.' {
+		i += 1
+		for i < len(expression) && isDigit(rune(expression[i])) {
+			i += 1
+		}
+	}
+
+	// Порядок считается частью числа, только если после
+	// буквы e (и, возможно, знака) идет хотя бы одна цифра
+	if i < len(expression) && (expression[i] == 'e' || expression[i] == 'E') {
+		j := i + 1
+		if j < len(expression) && (expression[j] == '+' || expression[j] == '-') {
+			j += 1
+		}
+		if j < len(expression) && isDigit(rune(expression[j])) {
+			i = j
+			for i < len(expression) && isDigit(rune(expression[i])) {
+				i += 1
+			}
+		}
+	}
+
+	return i
+}
+
+/*
+isLetter проверяет, что символ это латинская буква. Буквы других
+алфавитов в выражении не допускаются, поэтому имена переменных
+и функций состоят только из символов ASCII
+*/
+func isLetter(ch rune) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+/*
+isDigit проверяет, что символ это десятичная цифра
+*/
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,73 @@
+package expression
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		expression string
+		values     []string
+		types      []TokenType
+	}{
+		{"1+2", []string{"1", "+", "2", ""},
+			[]TokenType{NumberToken, OperatorToken, NumberToken, EndToken}},
+		{" 12 * (x1-.5) ", []string{"12", "*", "(", "x1", "-", ".5", ")", ""},
+			[]TokenType{NumberToken, OperatorToken, LeftBracketToken, IdentifierToken,
+				OperatorToken, NumberToken, RightBracketToken, EndToken}},
+		{"1e3+2.5E-4", []string{"1e3", "+", "2.5E-4", ""},
+			[]TokenType{NumberToken, OperatorToken, NumberToken, EndToken}},
+		{"7//2%3^2", []string{"7", "//", "2", "%", "3", "^", "2", ""},
+			[]TokenType{NumberToken, OperatorToken, NumberToken, OperatorToken,
+				NumberToken, OperatorToken, NumberToken, EndToken}},
+		{"pow(a_b,2)", []string{"pow", "(", "a_b", ",", "2", ")", ""},
+			[]TokenType{IdentifierToken, LeftBracketToken, IdentifierToken, CommaToken,
+				NumberToken, RightBracketToken, EndToken}},
+		{"2e", []string{"2", "e", ""},
+			[]TokenType{NumberToken, IdentifierToken, EndToken}},
+	}
+
+	for _, test := range tests {
+		tokens, err := Tokenize(test.expression)
+		if err != nil {
+			t.Errorf("Tokenize(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		values := make([]string, 0, len(tokens))
+		types := make([]TokenType, 0, len(tokens))
+		for _, token := range tokens {
+			values = append(values, token.Value)
+			types = append(types, token.Type)
+		}
+		if !reflect.DeepEqual(values, test.values) || !reflect.DeepEqual(types, test.types) {
+			t.Errorf("Tokenize(%q) = %v %v, want %v %v", test.expression, values, types, test.values, test.types)
+		}
+	}
+}
+
+func TestTokenizeUnexpectedSymbol(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+		token      string
+	}{
+		{"1 $ 2", 2, "$"},
+		{"α+1", 0, "α"},
+		{"1+α", 2, "α"},
+		{"1+2;", 3, ";"},
+	}
+
+	for _, test := range tests {
+		_, err := Tokenize(test.expression)
+		syntaxError, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Tokenize(%q) error = %v, want *SyntaxError", test.expression, err)
+			continue
+		}
+		if syntaxError.Position != test.position || syntaxError.Token != test.token {
+			t.Errorf("Tokenize(%q) error at %v %q, want %v %q",
+				test.expression, syntaxError.Position, syntaxError.Token, test.position, test.token)
+		}
+	}
+}
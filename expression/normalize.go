@@ -0,0 +1,54 @@
+package expression
+
+import (
+	"strings"
+)
+
+/*
+Normalize приводит выражение к каноничному виду, по которому
+можно сравнивать выражения между собой. Пробелы и лишние скобки
+убираются, каждая операция берется в скобки, а числа записываются
+точной дробью, поэтому " 2 + 2*2 ", "2+(2*2)" и "2.0+2*2"
+имеют одинаковый каноничный вид (2+(2*2))
+
+Parameters:
+
+	string: Входное выражение в строке
+
+Returns:
+
+	string: Каноничный вид выражения
+	error: Ошибки разбора, всегда *SyntaxError
+*/
+func Normalize(expression string) (string, error) {
+	tree, err := Parse(expression)
+	if err != nil {
+		return "", err
+	}
+	return tree.Canonical(), nil
+}
+
+/*
+Canonical восстанавливает выражение из дерева так же, как String,
+но числа записывает точной дробью из их записи в выражении
+*/
+func (n *Node) Canonical() string {
+	if n.IsVariable() {
+		return n.Variable
+	}
+	if n.IsLeaf() {
+		number, err := n.Number(RationalMode)
+		if err != nil {
+			return n.Literal
+		}
+		return number.String()
+	}
+	if n.IsFunction() {
+		arguments := make([]string, 0, len(n.Arguments))
+		for _, argument := range n.Arguments {
+			arguments = append(arguments, argument.Canonical())
+		}
+		return n.Operation + "(" + strings.Join(arguments, ",") + ")"
+	}
+	return "(" + n.Left.Canonical() + n.Operation + n.Right.Canonical() + ")"
+}
@@ -0,0 +1,157 @@
+package expression
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/*
+Режимы вычисления. В режиме FloatMode числа хранятся
+в float64, в режиме RationalMode в виде точной дроби
+big.Rat, поэтому 1/3*3 дает ровно 1
+*/
+const (
+	FloatMode    = "float"
+	RationalMode = "rational"
+)
+
+/*
+DefaultPrecision количество знаков после запятой
+в десятичной записи точного результата по умолчанию
+*/
+const DefaultPrecision = 10
+
+/*
+MaxPrecision наибольшее количество знаков после запятой
+в десятичной записи точного результата
+*/
+const MaxPrecision = 1000
+
+/*
+maxExactBits наибольший размер точной дроби в битах (числитель
+и знаменатель вместе, это примерно 2500 десятичных знаков).
+Операция, результат которой больше, завершается ошибкой,
+что бы дроби в цепочке операций не росли без ограничения
+*/
+const maxExactBits = 8192
+
+/*
+Number описывает число, с которым работает вычислитель.
+В режиме FloatMode заполнено поле Float, в режиме
+RationalMode поле Rational
+*/
+type Number struct {
+	Float    float64
+	Rational *big.Rat
+}
+
+/*
+IsValidMode возвращает true, если режим вычисления известен.
+Пустая строка означает режим по умолчанию FloatMode
+*/
+func IsValidMode(mode string) bool {
+	return mode == "" || mode == FloatMode || mode == RationalMode
+}
+
+/*
+ParseNumber переводит строку в число нужного режима.
+Строка может быть записью числа из выражения (12, 0.5, 1e3),
+а в режиме RationalMode еще и дробью вида 1/3
+
+Parameters:
+
+	string: Число в строке
+	string: Режим вычисления
+
+Returns:
+
+	Number: Число
+	error: Ошибки разбора числа
+*/
+func ParseNumber(text string, mode string) (Number, error) {
+	if mode == RationalMode {
+		rational, ok := new(big.Rat).SetString(text)
+		if !ok {
+			return Number{}, fmt.Errorf("Invalid number: %v", text)
+		}
+		if err := checkExactSize(rational); err != nil {
+			return Number{}, err
+		}
+		return Number{Rational: rational}, nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return Number{}, fmt.Errorf("Invalid number: %v", text)
+	}
+	return Number{Float: value}, nil
+}
+
+/*
+IsZero возвращает true, если число равно нулю
+*/
+func (n Number) IsZero() bool {
+	if n.Rational != nil {
+		return n.Rational.Sign() == 0
+	}
+	return n.Float == 0.0
+}
+
+/*
+Float64 возвращает значение числа в виде float64,
+точная дробь при этом округляется
+*/
+func (n Number) Float64() float64 {
+	if n.Rational != nil {
+		value, _ := n.Rational.Float64()
+		return value
+	}
+	return n.Float
+}
+
+/*
+String возвращает точную запись числа: для float64
+кратчайшую запись без потери точности, для дроби
+запись вида 1/3 или 5, если дробь целая
+*/
+func (n Number) String() string {
+	if n.Rational != nil {
+		return n.Rational.RatString()
+	}
+	return strconv.FormatFloat(n.Float, 'g', -1, 64)
+}
+
+/*
+Decimal возвращает десятичную запись числа с указанным
+количеством знаков после запятой. Для float64 возвращается
+обычная запись числа
+*/
+func (n Number) Decimal(precision int) string {
+	if n.Rational != nil {
+		return n.Rational.FloatString(precision)
+	}
+	return n.String()
+}
+
+/*
+checkExactSize возвращает ошибку, если точная дробь
+больше maxExactBits
+*/
+func checkExactSize(value *big.Rat) error {
+	if value.Num().BitLen()+value.Denom().BitLen() > maxExactBits {
+		return fmt.Errorf("Exact number is too large: more than %v bits", maxExactBits)
+	}
+	return nil
+}
+
+/*
+negateLiteral меняет знак записи числа, например 5 -> -5, -5 -> 5
+*/
+func negateLiteral(literal string) string {
+	if strings.HasPrefix(literal, "-") {
+		return literal[1:]
+	}
+	return "-" + literal
+}
@@ -0,0 +1,136 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+/*
+Приоритеты встроенных операций, чем больше число, тем сильнее
+операция связывает свои операнды. Унарный знак связывает
+слабее возведения в степень, поэтому -2^2 = -(2^2)
+*/
+const (
+	AdditivePrecedence       = 1
+	MultiplicativePrecedence = 2
+	UnaryPrecedence          = 3
+	PowerPrecedence          = 4
+)
+
+/*
+builtinOperators содержит встроенные бинарные операции
+выражения, при запуске они записываются в реестр операций
+*/
+var builtinOperators = []Operation{
+	{
+		Symbol: "+", Kind: InfixOperation, Precedence: AdditivePrecedence,
+		Float: func(args []float64) (float64, error) {
+			return args[0] + args[1], nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Add(args[0], args[1]), nil
+		},
+	},
+	{
+		Symbol: "-", Kind: InfixOperation, Precedence: AdditivePrecedence,
+		Float: func(args []float64) (float64, error) {
+			return args[0] - args[1], nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Sub(args[0], args[1]), nil
+		},
+	},
+	{
+		Symbol: "*", Kind: InfixOperation, Precedence: MultiplicativePrecedence,
+		Float: func(args []float64) (float64, error) {
+			return args[0] * args[1], nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Mul(args[0], args[1]), nil
+		},
+	},
+	{
+		Symbol: "/", Kind: InfixOperation, Precedence: MultiplicativePrecedence,
+		Float: func(args []float64) (float64, error) {
+			if args[1] == 0 {
+				return 0, fmt.Errorf("Division by zero: %v / %v", args[0], args[1])
+			}
+			return args[0] / args[1], nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			if args[1].Sign() == 0 {
+				return nil, fmt.Errorf("Division by zero: %v / %v", args[0].RatString(), args[1].RatString())
+			}
+			return new(big.Rat).Quo(args[0], args[1]), nil
+		},
+	},
+	{
+		Symbol: "//", Kind: InfixOperation, Precedence: MultiplicativePrecedence,
+		Float: func(args []float64) (float64, error) {
+			if args[1] == 0 {
+				return 0, fmt.Errorf("Division by zero: %v // %v", args[0], args[1])
+			}
+			return math.Floor(args[0] / args[1]), nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			if args[1].Sign() == 0 {
+				return nil, fmt.Errorf("Division by zero: %v // %v", args[0].RatString(), args[1].RatString())
+			}
+			return new(big.Rat).SetInt(ratFloor(new(big.Rat).Quo(args[0], args[1]))), nil
+		},
+	},
+	{
+		Symbol: "%", Kind: InfixOperation, Precedence: MultiplicativePrecedence,
+		Float: func(args []float64) (float64, error) {
+			if args[1] == 0 {
+				return 0, fmt.Errorf("Division by zero: %v %% %v", args[0], args[1])
+			}
+			return args[0] - args[1]*math.Floor(args[0]/args[1]), nil
+		},
+		Rational: func(args []*big.Rat) (*big.Rat, error) {
+			if args[1].Sign() == 0 {
+				return nil, fmt.Errorf("Division by zero: %v %% %v", args[0].RatString(), args[1].RatString())
+			}
+			quotient := new(big.Rat).SetInt(ratFloor(new(big.Rat).Quo(args[0], args[1])))
+			return new(big.Rat).Sub(args[0], quotient.Mul(quotient, args[1])), nil
+		},
+	},
+	{
+		Symbol: "^", Kind: InfixOperation, Precedence: PowerPrecedence, RightAssociative: true,
+		Float:    floatPow,
+		Rational: ratPow,
+	},
+}
+
+/*
+matchOperator находит самый длинный знак операции,
+с которого начинается строка с позиции begin, например
+// а не / в выражении 7//2. Если операции нет, то
+возвращается пустая строка
+*/
+func matchOperator(expression string, begin int) string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	match := ""
+	for symbol, operation := range registry {
+		if operation.Kind == InfixOperation && len(symbol) > len(match) &&
+			strings.HasPrefix(expression[begin:], symbol) {
+			match = symbol
+		}
+	}
+	return match
+}
+
+/*
+ratFloor возвращает наибольшее целое, не превосходящее дробь
+*/
+func ratFloor(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if remainder.Sign() < 0 {
+		quotient.Sub(quotient, big.NewInt(1))
+	}
+	return quotient
+}
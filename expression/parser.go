@@ -0,0 +1,328 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Node описывает узел дерева выражения.
+Лист дерева содержит число и его запись в выражении
+(она нужна для точного режима вычисления) или имя
+переменной и ее позицию в выражении. Внутренний
+узел содержит операцию и два поддерева с операндами,
+а вызов функции ее имя и поддеревья с аргументами
+*/
+type Node struct {
+	Operation string
+	Value     float64
+	Literal   string
+	Variable  string
+	Position  int
+	Left      *Node
+	Right     *Node
+	Arguments []*Node
+}
+
+/*
+IsLeaf возвращает true, если узел является числом
+*/
+func (n *Node) IsLeaf() bool {
+	return n.Left == nil && n.Right == nil && len(n.Arguments) == 0
+}
+
+/*
+IsFunction возвращает true, если узел является вызовом функции
+*/
+func (n *Node) IsFunction() bool {
+	return len(n.Arguments) != 0
+}
+
+/*
+Operands возвращает поддеревья, от которых зависит узел:
+два операнда операции или аргументы функции
+*/
+func (n *Node) Operands() []*Node {
+	if n.IsFunction() {
+		return n.Arguments
+	}
+	if n.IsLeaf() {
+		return nil
+	}
+	return []*Node{n.Left, n.Right}
+}
+
+/*
+IsVariable возвращает true, если узел является переменной,
+значение которой еще не подставлено
+*/
+func (n *Node) IsVariable() bool {
+	return n.Variable != ""
+}
+
+/*
+Number возвращает значение листа дерева в нужном режиме вычисления.
+Для переменной без значения возвращается ошибка
+*/
+func (n *Node) Number(mode string) (Number, error) {
+	if n.IsVariable() {
+		return Number{}, unknownVariable(n)
+	}
+	if mode == RationalMode {
+		return ParseNumber(n.Literal, mode)
+	}
+	return Number{Float: n.Value}, nil
+}
+
+/*
+String восстанавливает выражение из дерева,
+каждая операция берется в скобки
+*/
+func (n *Node) String() string {
+	if n.IsVariable() {
+		return n.Variable
+	}
+	if n.IsLeaf() {
+		return strconv.FormatFloat(n.Value, 'g', -1, 64)
+	}
+	if n.IsFunction() {
+		arguments := make([]string, 0, len(n.Arguments))
+		for _, argument := range n.Arguments {
+			arguments = append(arguments, argument.String())
+		}
+		return n.Operation + "(" + strings.Join(arguments, ",") + ")"
+	}
+	return "(" + n.Left.String() + n.Operation + n.Right.String() + ")"
+}
+
+/*
+Parser описывает рекурсивный спуск по списку лексем.
+Бинарные операции разбираются по их приоритету из таблицы
+операций, от слабых к сильным. Грамматика выражения:
+
+	expression := term (('+' | '-') term)*
+	term       := unary (('*' | '/' | '//' | '%') unary)*
+	unary      := ('-' | '+') unary | power
+	power      := factor ('^' unary)?
+	factor     := number | identifier | call | '(' expression ')'
+	call       := identifier '(' expression (',' expression)* ')'
+
+Операции одного приоритета левоассоциативны, то есть
+1-2-3 разбирается как (1-2)-3, кроме возведения в степень:
+2^3^2 разбирается как 2^(3^2). Унарный минус
+перед числом становится знаком самого числа, а перед
+переменной или скобкой превращается в вычитание
+из нуля: -(1+2) -> 0-(1+2)
+*/
+type Parser struct {
+	tokens   []Token
+	position int
+}
+
+/*
+Parse разбирает выражение и строит его дерево
+
+Parameters:
+
+	string: Входное выражение в строке
+
+Returns:
+
+	*Node: Корень дерева выражения
+	error: Ошибки разбора, всегда *SyntaxError
+*/
+func Parse(expression string) (*Node, error) {
+	tokens, err := Tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	// Пустое выражение считать нечего
+	if tokens[0].Type == EndToken {
+		return nil, &SyntaxError{Position: 0, Token: "", Reason: "empty expression"}
+	}
+
+	parser := &Parser{
+		tokens:   tokens,
+		position: 0,
+	}
+
+	root, err := parser.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	// После разбора должны остаться только конец строки
+	if token := parser.current(); token.Type != EndToken {
+		return nil, unexpectedToken(token)
+	}
+
+	return root, nil
+}
+
+/*
+current возвращает текущую лексему
+*/
+func (p *Parser) current() Token {
+	return p.tokens[p.position]
+}
+
+/*
+next сдвигает парсер на следующую лексему и возвращает текущую
+*/
+func (p *Parser) next() Token {
+	token := p.tokens[p.position]
+	if token.Type != EndToken {
+		p.position += 1
+	}
+	return token
+}
+
+/*
+parseExpression разбирает выражение целиком
+*/
+func (p *Parser) parseExpression() (*Node, error) {
+	return p.parseBinary(AdditivePrecedence)
+}
+
+/*
+parseBinary разбирает цепочку операций, приоритет которых
+не меньше minPrecedence. Правый операнд левоассоциативной
+операции разбирается с большим приоритетом, поэтому 1-2-3
+собирается как (1-2)-3, а у правоассоциативной с тем же
+приоритетом, поэтому 2^3^2 собирается как 2^(3^2)
+*/
+func (p *Parser) parseBinary(minPrecedence int) (*Node, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.current().Type == OperatorToken {
+		operator, ok := LookupOperator(p.current().Value)
+		if !ok || operator.Precedence < minPrecedence {
+			break
+		}
+		p.next()
+
+		nextPrecedence := operator.Precedence + 1
+		if operator.RightAssociative {
+			nextPrecedence = operator.Precedence
+		}
+		right, err := p.parseBinary(nextPrecedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &Node{Operation: operator.Symbol, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+/*
+parseFactor разбирает число, переменную, вызов функции,
+выражение в скобках или операнд с унарным знаком
+*/
+func (p *Parser) parseFactor() (*Node, error) {
+	token := p.next()
+
+	switch token.Type {
+	case OperatorToken:
+		if token.Value != "-" && token.Value != "+" {
+			break
+		}
+		// Унарный знак относится ко всему, что связано сильнее него,
+		// поэтому -2^2 это -(2^2), а -2*3 это (-2)*3
+		node, err := p.parseBinary(UnaryPrecedence)
+		if err != nil {
+			return nil, err
+		}
+		if token.Value == "+" {
+			return node, nil
+		}
+		if node.IsLeaf() && !node.IsVariable() {
+			return &Node{Value: -node.Value, Literal: negateLiteral(node.Literal)}, nil
+		}
+		return &Node{Operation: "-", Left: &Node{Value: 0, Literal: "0"}, Right: node}, nil
+
+	case NumberToken:
+		value, err := strconv.ParseFloat(token.Value, 64)
+		if err != nil {
+			return nil, &SyntaxError{
+				Position: token.Position,
+				Token:    token.Value,
+				Reason:   fmt.Sprintf("invalid number '%v'", token.Value),
+			}
+		}
+		return &Node{Value: value, Literal: token.Value}, nil
+
+	case IdentifierToken:
+		if p.current().Type == LeftBracketToken {
+			return p.parseCall(token)
+		}
+		return &Node{Variable: token.Value, Position: token.Position}, nil
+
+	case LeftBracketToken:
+		node, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.Type != RightBracketToken {
+			syntaxError := unexpectedToken(closing)
+			syntaxError.Reason = "expected ')' but found " + strings.TrimPrefix(syntaxError.Reason, "unexpected ")
+			return nil, syntaxError
+		}
+		return node, nil
+
+	}
+
+	return nil, unexpectedToken(token)
+}
+
+/*
+parseCall разбирает вызов встроенной функции, имя которой
+уже прочитано. Проверяется, что функция существует
+и получила допустимое количество аргументов
+*/
+func (p *Parser) parseCall(name Token) (*Node, error) {
+	function, ok := LookupFunction(name.Value)
+	if !ok {
+		return nil, &SyntaxError{
+			Position: name.Position,
+			Token:    name.Value,
+			Reason:   fmt.Sprintf("unknown function '%v'", name.Value),
+		}
+	}
+
+	// Пропускаем открывающую скобку и читаем аргументы через запятую
+	p.next()
+	arguments := make([]*Node, 0)
+	for {
+		argument, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+
+		token := p.next()
+		if token.Type == RightBracketToken {
+			break
+		}
+		if token.Type != CommaToken {
+			syntaxError := unexpectedToken(token)
+			syntaxError.Reason = "expected ',' or ')' but found " + strings.TrimPrefix(syntaxError.Reason, "unexpected ")
+			return nil, syntaxError
+		}
+	}
+
+	if len(arguments) < function.MinArgs || function.MaxArgs >= 0 && len(arguments) > function.MaxArgs {
+		return nil, &SyntaxError{
+			Position: name.Position,
+			Token:    name.Value,
+			Reason:   fmt.Sprintf("function '%v' expects %v but got %v", name.Value, function.arity(), len(arguments)),
+		}
+	}
+
+	return &Node{Operation: name.Value, Position: name.Position, Arguments: arguments}, nil
+}
@@ -0,0 +1,86 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		expression string
+		tree       string
+	}{
+		{"1+2*3", "(1+(2*3))"},
+		{"(1+2)*3", "((1+2)*3)"},
+		{"1-2-3", "((1-2)-3)"},
+		{"8/4/2", "((8/4)/2)"},
+		{"2^3^2", "(2^(3^2))"},
+		{"2*3^2", "(2*(3^2))"},
+		{"7//2%3", "((7//2)%3)"},
+		{"1+2//3", "(1+(2//3))"},
+		{"-2^2", "(0-(2^2))"},
+		{"-3*2", "(-3*2)"},
+		{"2*-1", "(2*-1)"},
+		{"-(1+2)", "(0-(1+2))"},
+		{"-x", "(0-x)"},
+		{"+5", "5"},
+		{"2^-1", "(2^-1)"},
+		{"pow(2, 1+1)", "pow(2,(1+1))"},
+		{"min(1,2,3)*2", "(min(1,2,3)*2)"},
+		{"((5))", "5"},
+	}
+
+	for _, test := range tests {
+		tree, err := Parse(test.expression)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		if tree.String() != test.tree {
+			t.Errorf("Parse(%q) = %v, want %v", test.expression, tree.String(), test.tree)
+		}
+	}
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+		column     int
+		token      string
+		reason     string
+	}{
+		{"", 0, 1, "", "empty expression"},
+		{"1+", 2, 3, "", "unexpected end of expression"},
+		{"1+2)", 3, 4, ")", "unexpected ')'"},
+		{"(1+2", 4, 5, "", "expected ')' but found end of expression"},
+		{"2*(3+)", 5, 6, ")", "unexpected ')'"},
+		{"sqrt()", 5, 6, ")", "unexpected ')'"},
+		{"1 $ 2", 2, 3, "$", "unexpected symbol '$'"},
+		{"foo(1)", 0, 1, "foo", "unknown function 'foo'"},
+		{"pow(1)", 0, 1, "pow", "function 'pow' expects 2 arguments but got 1"},
+		{"1+sqrt(1,2)", 2, 3, "sqrt", "function 'sqrt' expects 1 argument but got 2"},
+		{"round(1,2,3)", 0, 1, "round", "function 'round' expects 1 to 2 arguments but got 3"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.expression)
+		syntaxError, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("Parse(%q) error = %v, want *SyntaxError", test.expression, err)
+			continue
+		}
+		if syntaxError.Position != test.position || syntaxError.Column() != test.column ||
+			syntaxError.Token != test.token || syntaxError.Reason != test.reason {
+			t.Errorf("Parse(%q) error = {%v %v %q %q}, want {%v %v %q %q}", test.expression,
+				syntaxError.Position, syntaxError.Column(), syntaxError.Token, syntaxError.Reason,
+				test.position, test.column, test.token, test.reason)
+		}
+	}
+}
+
+func TestSyntaxErrorMessage(t *testing.T) {
+	_, err := Parse("1+2)")
+	if err == nil || err.Error() != "unexpected ')' at column 4" {
+		t.Errorf("Parse(%q) error = %v, want %q", "1+2)", err, "unexpected ')' at column 4")
+	}
+}
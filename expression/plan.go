@@ -0,0 +1,85 @@
+package expression
+
+import (
+	"sort"
+	"time"
+)
+
+/*
+PlannedOperation описывает одну операцию плана вычисления:
+узел дерева, время от начала вычисления, когда операция
+будет запущена, и время, когда она закончится
+*/
+type PlannedOperation struct {
+	Node     *Node
+	Duration time.Duration
+	Start    time.Duration
+	End      time.Duration
+}
+
+/*
+Stage описывает этап плана вычисления, то есть группу
+операций, которые планировщик запустит одновременно
+*/
+type Stage struct {
+	Start      time.Duration
+	Operations []PlannedOperation
+}
+
+/*
+PlanSchedule строит план вычисления дерева так, как его выполнит
+планировщик: каждая операция начинается, когда закончились
+все операции, от которых она зависит. Операции, которые
+начинаются одновременно, объединяются в один этап
+
+Parameters:
+
+	*Node: Корень дерева выражения
+	map[string]int: Время выполнения операций в секундах
+
+Returns:
+
+	[]Stage: Этапы вычисления в порядке их начала
+*/
+func PlanSchedule(root *Node, times map[string]int) []Stage {
+	operations := make([]PlannedOperation, 0)
+
+	var plan func(node *Node) time.Duration
+	plan = func(node *Node) time.Duration {
+		if node.IsLeaf() {
+			return 0
+		}
+
+		var start time.Duration
+		for _, operand := range node.Operands() {
+			if end := plan(operand); end > start {
+				start = end
+			}
+		}
+
+		duration := OperationTime(times, node.Operation)
+		operations = append(operations, PlannedOperation{
+			Node:     node,
+			Duration: duration,
+			Start:    start,
+			End:      start + duration,
+		})
+		return start + duration
+	}
+	plan(root)
+
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].Start < operations[j].Start
+	})
+
+	stages := make([]Stage, 0)
+	for _, operation := range operations {
+		if len(stages) == 0 || stages[len(stages)-1].Start != operation.Start {
+			stages = append(stages, Stage{Start: operation.Start})
+		}
+		last := &stages[len(stages)-1]
+		last.Operations = append(last.Operations, operation)
+	}
+
+	return stages
+}
@@ -0,0 +1,363 @@
+package expression
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+/*
+Виды операций реестра
+*/
+const (
+	InfixOperation    = iota // Бинарная операция между операндами, например a+b
+	FunctionOperation        // Функция, записывается вызовом, например pow(a, b)
+)
+
+/*
+DefaultOperationTime время выполнения операции
+или функции в секундах, если оно не задано
+*/
+const DefaultOperationTime = 1
+
+/*
+Operation описывает запись реестра операций: знак операции
+или имя функции, вид, допустимое количество аргументов
+(MaxArgs равный -1 означает, что аргументов может быть сколько
+угодно), приоритет и ассоциативность бинарной операции, время
+выполнения по умолчанию в секундах и реализации для вычислений
+в числах с плавающей точкой и в точных дробях. Если точной
+реализации нет, то операция считается через float64,
+а результат переводится обратно в дробь
+*/
+type Operation struct {
+	Symbol           string
+	Kind             int
+	MinArgs          int
+	MaxArgs          int
+	Precedence       int
+	RightAssociative bool
+	DefaultTime      int
+	Float            func(args []float64) (float64, error)
+	Rational         func(args []*big.Rat) (*big.Rat, error)
+	order            int
+}
+
+/*
+registry содержит все операции и функции выражения.
+По нему работают лексер, парсер, модель стоимости,
+вычислители и проверка настроек в оркестраторе
+*/
+var (
+	registry      = make(map[string]*Operation)
+	registryMutex sync.RWMutex
+)
+
+func init() {
+	for _, operation := range builtinOperators {
+		mustRegister(operation)
+	}
+	for _, operation := range builtinFunctions {
+		mustRegister(operation)
+	}
+}
+
+/*
+Register добавляет в реестр новую операцию или функцию.
+Регистрировать операции нужно до начала работы сервисов,
+например в init() файла пакета expression, тогда операция
+появится сразу во фронтенде, оркестраторе и вычислителях.
+Бинарной операции, у которой не задано количество аргументов,
+оно выставляется равным двум, а пустому времени выполнения
+значение DefaultOperationTime
+
+Parameters:
+
+	Operation: Описание операции
+
+Returns:
+
+	error: Ошибка, если операция описана неверно
+	или операция с таким знаком уже есть
+*/
+func Register(operation Operation) error {
+	if operation.Float == nil {
+		return fmt.Errorf("Operation '%v' has no implementation", operation.Symbol)
+	}
+	if operation.DefaultTime < 0 {
+		return fmt.Errorf("Operation '%v' has negative default time", operation.Symbol)
+	}
+	if operation.DefaultTime == 0 {
+		operation.DefaultTime = DefaultOperationTime
+	}
+
+	switch operation.Kind {
+	case InfixOperation:
+		if !isOperatorSymbol(operation.Symbol) {
+			return fmt.Errorf("Operation '%v' has invalid symbol", operation.Symbol)
+		}
+		if operation.MinArgs == 0 && operation.MaxArgs == 0 {
+			operation.MinArgs, operation.MaxArgs = 2, 2
+		}
+		if operation.MinArgs != 2 || operation.MaxArgs != 2 {
+			return fmt.Errorf("Operation '%v' must take 2 operands", operation.Symbol)
+		}
+		if operation.Precedence < AdditivePrecedence {
+			return fmt.Errorf("Operation '%v' has invalid precedence %v", operation.Symbol, operation.Precedence)
+		}
+
+	case FunctionOperation:
+		if !isIdentifier(operation.Symbol) {
+			return fmt.Errorf("Function '%v' has invalid name", operation.Symbol)
+		}
+		if operation.MinArgs < 1 || operation.MaxArgs >= 0 && operation.MaxArgs < operation.MinArgs {
+			return fmt.Errorf("Function '%v' has invalid number of arguments", operation.Symbol)
+		}
+
+	default:
+		return fmt.Errorf("Operation '%v' has unknown kind %v", operation.Symbol, operation.Kind)
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, ok := registry[operation.Symbol]; ok {
+		return fmt.Errorf("Operation '%v' is already registered", operation.Symbol)
+	}
+	operation.order = len(registry)
+	registry[operation.Symbol] = &operation
+	return nil
+}
+
+/*
+mustRegister добавляет в реестр встроенную операцию,
+ошибка в ее описании является ошибкой программиста
+*/
+func mustRegister(operation Operation) {
+	if err := Register(operation); err != nil {
+		panic(err)
+	}
+}
+
+/*
+Lookup возвращает операцию или функцию по ее знаку или имени
+*/
+func Lookup(symbol string) (*Operation, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	operation, ok := registry[symbol]
+	return operation, ok
+}
+
+/*
+LookupOperator возвращает бинарную операцию по ее знаку
+*/
+func LookupOperator(symbol string) (*Operation, bool) {
+	operation, ok := Lookup(symbol)
+	if !ok || operation.Kind != InfixOperation {
+		return nil, false
+	}
+	return operation, true
+}
+
+/*
+LookupFunction возвращает функцию по ее имени
+*/
+func LookupFunction(name string) (*Operation, bool) {
+	operation, ok := Lookup(name)
+	if !ok || operation.Kind != FunctionOperation {
+		return nil, false
+	}
+	return operation, true
+}
+
+/*
+Operations возвращает знаки всех операций и имена всех
+функций выражения, время выполнения которых можно настроить.
+Сначала идут бинарные операции по возрастанию приоритета,
+затем функции по алфавиту
+*/
+func Operations() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	operations := make([]*Operation, 0, len(registry))
+	for _, operation := range registry {
+		operations = append(operations, operation)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		a, b := operations[i], operations[j]
+		if a.Kind != b.Kind {
+			return a.Kind == InfixOperation
+		}
+		if a.Kind == FunctionOperation {
+			return a.Symbol < b.Symbol
+		}
+		if a.Precedence != b.Precedence {
+			return a.Precedence < b.Precedence
+		}
+		return a.order < b.order
+	})
+
+	symbols := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		symbols = append(symbols, operation.Symbol)
+	}
+	return symbols
+}
+
+/*
+IsOperation возвращает true, если это знак операции
+или имя функции из реестра
+*/
+func IsOperation(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+/*
+DefaultTimes возвращает словарь со временем выполнения
+по умолчанию для всех операций и функций выражения
+*/
+func DefaultTimes() map[string]int {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	times := make(map[string]int)
+	for symbol, operation := range registry {
+		times[symbol] = operation.DefaultTime
+	}
+	return times
+}
+
+/*
+OperationTime возвращает время выполнения операции из словаря
+times, а если его там нет, то время по умолчанию из реестра
+*/
+func OperationTime(times map[string]int, symbol string) time.Duration {
+	if seconds, ok := times[symbol]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	if operation, ok := Lookup(symbol); ok {
+		return time.Duration(operation.DefaultTime) * time.Second
+	}
+	return 0
+}
+
+/*
+arity возвращает описание допустимого количества
+аргументов функции, например "2 arguments"
+*/
+func (o *Operation) arity() string {
+	switch {
+	case o.MaxArgs < 0:
+		return fmt.Sprintf("at least %v arguments", o.MinArgs)
+	case o.MinArgs == o.MaxArgs && o.MinArgs == 1:
+		return "1 argument"
+	case o.MinArgs == o.MaxArgs:
+		return fmt.Sprintf("%v arguments", o.MinArgs)
+	default:
+		return fmt.Sprintf("%v to %v arguments", o.MinArgs, o.MaxArgs)
+	}
+}
+
+/*
+call вычисляет операцию от уже посчитанных аргументов.
+Если все аргументы точные дроби, то операция считается точно,
+а результат больше maxExactBits считается ошибкой. Результат
+вычисления через float64 должен быть конечным числом
+*/
+func (o *Operation) call(args []Number) (Number, error) {
+	if o.MaxArgs >= 0 && len(args) > o.MaxArgs || len(args) < o.MinArgs {
+		return Number{}, fmt.Errorf("Operation %v can not take %v arguments", o.Symbol, len(args))
+	}
+
+	rationals := make([]*big.Rat, 0, len(args))
+	floats := make([]float64, 0, len(args))
+	for _, arg := range args {
+		if arg.Rational != nil {
+			rationals = append(rationals, arg.Rational)
+		}
+		floats = append(floats, arg.Float64())
+	}
+
+	if len(rationals) == len(args) && o.Rational != nil {
+		res, err := o.Rational(rationals)
+		if err != nil {
+			return Number{}, err
+		}
+		if err = checkExactSize(res); err != nil {
+			return Number{}, err
+		}
+		return Number{Rational: res}, nil
+	}
+
+	res, err := o.Float(floats)
+	if err != nil {
+		return Number{}, err
+	}
+	if err = o.checkFinite(floats, res); err != nil {
+		return Number{}, err
+	}
+
+	// Операцию без точной реализации в точном режиме
+	// считаем через float64 и переводим результат в дробь
+	if len(rationals) == len(args) {
+		return Number{Rational: new(big.Rat).SetFloat64(res)}, nil
+	}
+	return Number{Float: res}, nil
+}
+
+/*
+checkFinite возвращает ошибку, если результат операции
+над float64 бесконечность или не число (NaN), например 1e308*10
+*/
+func (o *Operation) checkFinite(args []float64, res float64) error {
+	if !math.IsNaN(res) && !math.IsInf(res, 0) {
+		return nil
+	}
+
+	if o.Kind == InfixOperation && len(args) == 2 {
+		return fmt.Errorf("Result is not a finite number: %v %v %v", args[0], o.Symbol, args[1])
+	}
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, fmt.Sprint(arg))
+	}
+	return fmt.Errorf("Result is not a finite number: %v(%v)", o.Symbol, strings.Join(parts, ", "))
+}
+
+/*
+isIdentifier проверяет, что строка может быть именем функции
+или переменной: латинские буквы, цифры и знак подчеркивания,
+первой не может быть цифра
+*/
+func isIdentifier(name string) bool {
+	for i, ch := range name {
+		if !isLetter(ch) && ch != '_' && (i == 0 || !isDigit(ch)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+/*
+isOperatorSymbol проверяет, что строка может быть знаком бинарной
+операции: в ней нет букв, цифр, пробелов, скобок, запятых и точек,
+иначе лексер не сможет отличить операцию от других лексем
+*/
+func isOperatorSymbol(symbol string) bool {
+	for _, ch := range symbol {
+		if unicode.IsLetter(ch) || unicode.IsDigit(ch) || unicode.IsSpace(ch) || ch == '_' ||
+			strings.ContainsRune("().,", ch) {
+			return false
+		}
+	}
+	return symbol != ""
+}
@@ -0,0 +1,58 @@
+package expression
+
+import (
+	"fmt"
+	"sort"
+)
+
+/*
+Validate проверяет, что выражение можно вычислить:
+оно непустое, составлено из чисел, переменных, бинарных
+операций и вызовов функций из реестра операций (с допустимым
+для функции количеством аргументов) и скобок, а скобки
+сбалансированы. Числа могут быть
+дробными (0.5, .5) и записанными в научной нотации (1e3),
+перед числом или скобкой допускается унарный знак.
+Для каждой переменной выражения должно быть передано значение,
+а каждое переданное имя должно быть именем переменной выражения
+
+Parameters:
+
+	string: Входное выражение в строке
+	map[string]float64: Значения переменных выражения
+
+Returns:
+
+	error: *SyntaxError с позицией и причиной, по которой
+	выражение не валидно, ошибка в именах переданных
+	переменных или nil
+*/
+func Validate(expression string, variables map[string]float64) error {
+	tree, err := Parse(expression)
+	if err != nil {
+		return err
+	}
+
+	// Имена переданных переменных сохраняются вместе с задачей и
+	// показываются клиентам, поэтому лишние и неверные имена не принимаем
+	used := make(map[string]bool)
+	for _, name := range Variables(tree) {
+		used[name] = true
+	}
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !isIdentifier(name) {
+			return fmt.Errorf("Invalid variable name: %q", name)
+		}
+		if !used[name] {
+			return fmt.Errorf("Variable %v is not used in expression", name)
+		}
+	}
+
+	_, err = Bind(tree, variables)
+	return err
+}
@@ -0,0 +1,30 @@
+package expression
+
+import (
+	"testing"
+)
+
+func TestValidateVariables(t *testing.T) {
+	tests := []struct {
+		expression string
+		variables  map[string]float64
+		ok         bool
+	}{
+		{"a*b+c", map[string]float64{"a": 2, "b": 3, "c": 4}, true},
+		{"x_1+1", map[string]float64{"x_1": 1}, true},
+		{"1+2", nil, true},
+		{"a+1", nil, false},
+		{"a+1", map[string]float64{"b": 1}, false},
+		{"a+1", map[string]float64{"a": 1, "b": 1}, false},
+		{"1+2", map[string]float64{"<img src=x onerror=alert(1)>": 1}, false},
+		{"a+1", map[string]float64{"a": 1, "1a": 1}, false},
+		{"a+1", map[string]float64{"a": 1, "α": 1}, false},
+	}
+
+	for _, test := range tests {
+		err := Validate(test.expression, test.variables)
+		if (err == nil) != test.ok {
+			t.Errorf("Validate(%q, %v) = %v, want ok %v", test.expression, test.variables, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package expression
+
+import (
+	"sort"
+	"strconv"
+)
+
+/*
+Variables возвращает отсортированный список имен
+переменных, которые встречаются в дереве выражения
+*/
+func Variables(root *Node) []string {
+	seen := make(map[string]bool)
+	var collect func(node *Node)
+	collect = func(node *Node) {
+		if node.IsVariable() {
+			seen[node.Variable] = true
+			return
+		}
+		for _, operand := range node.Operands() {
+			collect(operand)
+		}
+	}
+	collect(root)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+Bind подставляет значения переменных в дерево выражения.
+Исходное дерево не меняется, возвращается его копия,
+в которой каждая переменная заменена числом. Запись числа
+берется кратчайшая, поэтому в точном режиме вычисления
+значение 0.1 превратится ровно в 1/10
+
+Parameters:
+
+	*Node: Корень дерева выражения
+	map[string]float64: Значения переменных
+
+Returns:
+
+	*Node: Корень дерева без переменных
+	error: *SyntaxError, если для переменной нет значения
+*/
+func Bind(root *Node, variables map[string]float64) (*Node, error) {
+	if root.IsVariable() {
+		value, ok := variables[root.Variable]
+		if !ok {
+			return nil, unknownVariable(root)
+		}
+		return &Node{Value: value, Literal: strconv.FormatFloat(value, 'g', -1, 64)}, nil
+	}
+	if root.IsLeaf() {
+		return root, nil
+	}
+
+	operands := make([]*Node, 0, len(root.Operands()))
+	for _, operand := range root.Operands() {
+		bound, err := Bind(operand, variables)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, bound)
+	}
+
+	if root.IsFunction() {
+		return &Node{Operation: root.Operation, Position: root.Position, Arguments: operands}, nil
+	}
+	return &Node{Operation: root.Operation, Left: operands[0], Right: operands[1]}, nil
+}
+
+/*
+ParseWithVariables разбирает выражение и подставляет
+в его дерево значения переменных
+
+Parameters:
+
+	string: Входное выражение в строке
+	map[string]float64: Значения переменных выражения
+
+Returns:
+
+	*Node: Корень дерева выражения без переменных
+	error: Ошибки разбора, всегда *SyntaxError
+*/
+func ParseWithVariables(expression string, variables map[string]float64) (*Node, error) {
+	tree, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return Bind(tree, variables)
+}
@@ -3,3 +3,7 @@ module frontend_server
 go 1.20
 
 require github.com/Knetic/govaluate v3.0.0+incompatible // indirect
+
+require expression v0.0.0
+
+replace expression => ../expression
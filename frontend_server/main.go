@@ -16,9 +16,14 @@ func main() {
 		Executors: []pkg.Executor{
 			pkg.NewSiteUpExecutor(),
 			pkg.NewGetExpressionFromFirstPage(),
+			pkg.NewSendExpressionsFromFirstPage(),
+			pkg.NewGetTasksFromIDsFromFirstPage(),
+			pkg.NewExplainExpressionFromFirstPage(),
 			pkg.NewGetListOfTasksFromSecondPage(),
 			pkg.NewSendMessageWithTimeOfOperations(),
+			pkg.NewGetTimeOfOperationsFromThirdPage(),
 			pkg.NewGetListOfSolversFromFourthPage(),
+			pkg.NewGetEventsFromAllPages(),
 		},
 	}
 
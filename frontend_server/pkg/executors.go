@@ -3,14 +3,13 @@ package pkg
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"expression"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
-	"regexp"
-	"strconv"
-	"strings"
 	"time"
 	//"github.com/Knetic/govaluate"
 )
@@ -64,12 +63,24 @@ SendExpressionFromFirstPage принимает запрос с выражени
 и отправляет запрос(с выражением) на сервер-оркестратор
 */
 type ExpressionJSON struct {
-	Expression string `json:"expression"`
+	Expression         string             `json:"expression"`
+	NumericMode        string             `json:"numericMode"`
+	Precision          int                `json:"precision"`
+	Variables          map[string]float64 `json:"variables"`
+	SimulateCachedTime bool               `json:"simulateCachedTime"`
+	IdempotencyKey     string             `json:"idempotencyKey"`
+	CallbackURL        string             `json:"callbackUrl"`
 }
 
 type ExpressionRequestJSON struct {
-	Expression string    `json:"expression"`
-	TimeToSend time.Time `json:"timeToSend"`
+	Expression         string             `json:"expression"`
+	TimeToSend         time.Time          `json:"timeToSend"`
+	NumericMode        string             `json:"numericMode"`
+	Precision          int                `json:"precision"`
+	Variables          map[string]float64 `json:"variables"`
+	SimulateCachedTime bool               `json:"simulateCachedTime"`
+	IdempotencyKey     string             `json:"idempotencyKey"`
+	CallbackURL        string             `json:"callbackUrl"`
 }
 
 type SendExpressionFromFirstPage struct{}
@@ -94,18 +105,30 @@ func (e *SendExpressionFromFirstPage) getExecutorHandler() func(w http.ResponseW
 			return
 		}
 
-		// Проверяем валидность выражения
-		isValid := isValidExpression(message.Expression)
-		if !isValid {
-			http.Error(w, "[ERROR]: Can not parse expression", http.StatusBadRequest)
-			log.Println("[ERROR]: Can not parse expression")
+		// Проверяем валидность выражения, если выражение
+		// не разбирается, то возвращаем позицию и причину ошибки
+		err = expression.Validate(message.Expression, message.Variables)
+		if err != nil {
+			writeValidationError(w, err)
+			log.Println("[ERROR]: Can not parse expression: " + err.Error())
 			return
 		}
 
+		// Ключ идемпотентности из заголовка передаем оркестратору в поле JSON
+		if message.IdempotencyKey == "" {
+			message.IdempotencyKey = r.Header.Get("Idempotency-Key")
+		}
+
 		// Если удалось успешно то пробуем отправить запрос на бэкенд
 		requestToBack := ExpressionRequestJSON{
-			Expression: message.Expression,
-			TimeToSend: time.Now(),
+			Expression:         message.Expression,
+			TimeToSend:         time.Now(),
+			NumericMode:        message.NumericMode,
+			Precision:          message.Precision,
+			Variables:          message.Variables,
+			SimulateCachedTime: message.SimulateCachedTime,
+			IdempotencyKey:     message.IdempotencyKey,
+			CallbackURL:        message.CallbackURL,
 		}
 
 		// Формируем JSON
@@ -125,36 +148,245 @@ func (e *SendExpressionFromFirstPage) getExecutorHandler() func(w http.ResponseW
 		}
 		defer resp.Body.Close()
 
+		// Если оркестратор отказал в приеме выражения,
+		// то передаем его ответ на веб страницу как есть
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+			w.WriteHeader(resp.StatusCode)
+			w.Write(body)
+			log.Println("[ERROR]: Orchestrator rejected expression: " + string(body))
+			return
+		}
+
+		// Передаем на веб страницу номер созданной задачи
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not read response: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not read response: " + err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		w.Write(body)
 		log.Println("[OK]: Resive expression was successful")
 	}
 }
 
-func isValidExpression(expr string) bool {
-	pattern1 := regexp.MustCompile(`[\d\+\-\*/]`)
-	pattern2 := regexp.MustCompile(`[\+\-\*/]`)
-	arr := strings.Split(expr, "")
-	for i, ch := range arr {
-		if !pattern1.MatchString(ch) {
-			return false
+/*
+SendExpressionsFromFirstPage принимает запрос с массивом выражений,
+например вставленных списком на первой вкладке, и отправляет их
+на сервер-оркестратор одним запросом. Каждое выражение проверяет
+оркестратор, ответ с номерами задач и ошибками передается как есть
+*/
+type SendExpressionsFromFirstPage struct{}
+
+func NewSendExpressionsFromFirstPage() *SendExpressionsFromFirstPage {
+	return &SendExpressionsFromFirstPage{}
+}
+
+func (e *SendExpressionsFromFirstPage) getExecutorRoute() string {
+	return "/sendExpressions"
+}
+
+func (e *SendExpressionsFromFirstPage) getExecutorHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Декодируем тело запроса в массив выражений
+		var messages []ExpressionJSON
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&messages)
+		if err != nil {
+			http.Error(w, "[ERROR]: Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: Decoding JSON was failed: " + err.Error())
+			return
 		}
 
-		if (i != len(expr)-1) &&
-			pattern2.MatchString(arr[i]) &&
-			pattern2.MatchString(arr[i+1]) {
-			return false
+		requestToBack := make([]ExpressionRequestJSON, 0, len(messages))
+		for _, message := range messages {
+			requestToBack = append(requestToBack, ExpressionRequestJSON{
+				Expression:         message.Expression,
+				TimeToSend:         time.Now(),
+				NumericMode:        message.NumericMode,
+				Precision:          message.Precision,
+				Variables:          message.Variables,
+				SimulateCachedTime: message.SimulateCachedTime,
+				IdempotencyKey:     message.IdempotencyKey,
+				CallbackURL:        message.CallbackURL,
+			})
 		}
 
-		if i == 0 && (ch == "*" || ch == "/") {
-			return false
+		// Формируем JSON
+		jsonRequest, err := json.Marshal(requestToBack)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not encoding to JSON: " + err.Error())
+			return
 		}
 
-		if (i == len(expr)-1) && pattern2.MatchString(ch) {
-			return false
+		// Пробует отправить запрос на бэк
+		resp, err := http.Post("http://orchestrator_server:8082/addArithmeticExpressions", "application/json", bytes.NewBuffer(jsonRequest))
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not send JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not send JSON: " + err.Error())
+			return
 		}
+		defer resp.Body.Close()
+
+		// Передаем ответ оркестратора на веб страницу как есть
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not read response: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not read response: " + err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+
+		log.Println("[OK]: Resive expressions was successful")
 	}
+}
 
-	return true
+/*
+GetTasksFromIDsFromFirstPage принимает запрос со списком
+номеров задач и возвращает эти задачи, полученные от оркестратора
+*/
+type GetTasksFromIDsFromFirstPage struct{}
+
+func NewGetTasksFromIDsFromFirstPage() *GetTasksFromIDsFromFirstPage {
+	return &GetTasksFromIDsFromFirstPage{}
+}
+
+func (e *GetTasksFromIDsFromFirstPage) getExecutorRoute() string {
+	return "/getTasksFromIDs"
+}
+
+func (e *GetTasksFromIDsFromFirstPage) getExecutorHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Пробует отправить запрос со списком номеров на бэк
+		resp, err := http.Post("http://orchestrator_server:8082/getListOfTasksFromIDs", "application/json", r.Body)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not send JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not send JSON: " + err.Error())
+			return
+		}
+		defer resp.Body.Close()
+
+		// Вытаскиваем тело из ответа, в котором зашифрован JSON
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not read response: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not read response: " + err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+
+		log.Println("[OK]: Send tasks from ids was successful")
+	}
+}
+
+/*
+ExplainExpressionFromFirstPage принимает запрос с выражением
+и возвращает план его вычисления, полученный от оркестратора
+*/
+type ExplainExpressionFromFirstPage struct{}
+
+func NewExplainExpressionFromFirstPage() *ExplainExpressionFromFirstPage {
+	return &ExplainExpressionFromFirstPage{}
+}
+
+func (e *ExplainExpressionFromFirstPage) getExecutorRoute() string {
+	return "/explainExpression"
+}
+
+func (e *ExplainExpressionFromFirstPage) getExecutorHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Декодируем тело запроса в JSON нужной нам структуры
+		var message ExpressionJSON
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&message)
+		if err != nil {
+			http.Error(w, "[ERROR]: Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: Decoding JSON was failed: " + err.Error())
+			return
+		}
+
+		// Формируем JSON
+		jsonRequest, err := json.Marshal(message)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not encoding to JSON: " + err.Error())
+			return
+		}
+
+		// Пробует отправить запрос на бэк для получения плана вычисления
+		resp, err := http.Post("http://orchestrator_server:8082/explainExpression", "application/json", bytes.NewBuffer(jsonRequest))
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not send JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not send JSON: " + err.Error())
+			return
+		}
+		defer resp.Body.Close()
+
+		// Вытаскиваем тело из ответа, в котором зашифрован JSON
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, "Error reading response from server", http.StatusInternalServerError)
+			return
+		}
+
+		// Передаем ответ оркестратора вместе с его кодом,
+		// что бы веб страница могла подсветить ошибку разбора
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
+		w.Write(body)
+
+		log.Println("[OK]: Send expression plan was successful")
+	}
+}
+
+/*
+ValidationErrorJSON описывает ответ веб странице с ошибкой
+разбора выражения: текст ошибки, позицию ошибки в байтах,
+номер колонки, лексему на этой позиции и причину ошибки
+*/
+type ValidationErrorJSON struct {
+	Error    string `json:"error"`
+	Position int    `json:"position"`
+	Column   int    `json:"column"`
+	Token    string `json:"token"`
+	Reason   string `json:"reason"`
+}
+
+/*
+writeValidationError отправляет веб странице ошибку разбора
+выражения в виде JSON с кодом 400
+*/
+func writeValidationError(w http.ResponseWriter, err error) {
+	response := ValidationErrorJSON{
+		Error:  err.Error(),
+		Reason: err.Error(),
+	}
+
+	var syntaxError *expression.SyntaxError
+	if errors.As(err, &syntaxError) {
+		response.Position = syntaxError.Position
+		response.Column = syntaxError.Column()
+		response.Token = syntaxError.Token
+		response.Reason = syntaxError.Reason
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "[ERROR]: Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(jsonResponse)
 }
 
 /*
@@ -162,13 +394,19 @@ GetListOfTasksFromSecondPage принимает запрос
 и возвращает список со всеми задачами
 */
 type TaskJSON struct {
-	ID         int       `json:"id"`
-	Expression string    `json:"expression"`
-	HashID     string    `json:"hashId"`
-	Status     int       `json:"status"`
-	Result     string    `json:"result"`
-	BeginTime  time.Time `json:"beginTime"`
-	EndTime    time.Time `json:"endTime"`
+	ID            int                `json:"id"`
+	Expression    string             `json:"expression"`
+	HashID        string             `json:"hashId"`
+	Status        int                `json:"status"`
+	Result        string             `json:"result"`
+	BeginTime     time.Time          `json:"beginTime"`
+	EndTime       time.Time          `json:"endTime"`
+	ActualEndTime time.Time          `json:"actualEndTime"`
+	NumericMode   string             `json:"numericMode"`
+	Precision     int                `json:"precision"`
+	ResultDecimal string             `json:"resultDecimal"`
+	Variables     map[string]float64 `json:"variables"`
+	Cached        bool               `json:"cached"`
 }
 
 type GetListOfTasksFromSecondPage struct{}
@@ -183,8 +421,13 @@ func (e *GetListOfTasksFromSecondPage) getExecutorRoute() string {
 
 func (e *GetListOfTasksFromSecondPage) getExecutorHandler() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Пробует отправить запрос на бэк для получения списка задач
-		resp, err := http.Get("http://orchestrator_server:8082/getListOfTasks")
+		// Пробует отправить запрос на бэк для получения списка задач,
+		// параметры выборки передаются оркестратору без изменений
+		url := "http://orchestrator_server:8082/getListOfTasks"
+		if r.URL.RawQuery != "" {
+			url += "?" + r.URL.RawQuery
+		}
+		resp, err := http.Get(url)
 		if err != nil {
 			http.Error(w, "[ERROR]: Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
 			log.Println("[ERROR]: Can not encoding to JSON: " + err.Error())
@@ -199,9 +442,13 @@ func (e *GetListOfTasksFromSecondPage) getExecutorHandler() func(w http.Response
 			return
 		}
 
-		// Заполняем тело запроса и заголовки
-		w.WriteHeader(http.StatusOK)
-		w.Header().Set("Content-Type", "application/json")
+		// Заполняем тело запроса и заголовки, курсор следующей
+		// страницы и код ответа берем из ответа оркестратора
+		if cursor := resp.Header.Get("X-Next-Cursor"); cursor != "" {
+			w.Header().Set("X-Next-Cursor", cursor)
+		}
+		w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+		w.WriteHeader(resp.StatusCode)
 		w.Write(body)
 
 		log.Println("[OK]: Send list of tasks was successful")
@@ -210,17 +457,10 @@ func (e *GetListOfTasksFromSecondPage) getExecutorHandler() func(w http.Response
 
 /*
 SendMessageWithTimeOfOperations принимает запрос от
-веб страницы со временем выполнения для операций,
-и отправляет запрос(со временем выполнения для
-операций) на сервер-оркестратор
+веб страницы со временем выполнения для операций
+и встроенных функций, и отправляет запрос(со временем
+выполнения для операций) на сервер-оркестратор
 */
-type CalculateTimesJSON struct {
-	AdditionTime       string `json:"additionTime"`
-	SubtractionTime    string `json:"subtractionTime"`
-	DivisionTime       string `json:"divisionTime"`
-	MultiplicationTime string `json:"multiplicationTime"`
-}
-
 type TimeOfOperationJSON struct {
 	Times map[string]int `json:"times"`
 }
@@ -238,7 +478,7 @@ func (e *SendMessageWithTimeOfOperations) getExecutorRoute() string {
 func (e *SendMessageWithTimeOfOperations) getExecutorHandler() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Сообщение от фронта, содержащее задержки для каждой операции
-		var message CalculateTimesJSON
+		var message TimeOfOperationJSON
 
 		// Декодируем тело запроса в сообщение
 		decoder := json.NewDecoder(r.Body)
@@ -249,46 +489,17 @@ func (e *SendMessageWithTimeOfOperations) getExecutorHandler() func(w http.Respo
 			return
 		}
 
-		// Переводим строки в числовые значения времени в секундах
-		add, err := strconv.Atoi(message.AdditionTime)
-		if err != nil {
-			http.Error(w, "[ERROR]: Convert string to int was failed: "+err.Error(), http.StatusBadRequest)
-			log.Println("[ERROR]: Convert string to int was failed: " + err.Error())
-			return
-		}
-
-		sub, err := strconv.Atoi(message.SubtractionTime)
-		if err != nil {
-			http.Error(w, "[ERROR]: Convert string to int was failed: "+err.Error(), http.StatusBadRequest)
-			log.Println("[ERROR]: Convert string to int was failed: " + err.Error())
-			return
+		// Проверяем, что время задано только известным
+		// операциям и функциям и не бывает отрицательным
+		for key, val := range message.Times {
+			if !expression.IsOperation(key) || val < 0 {
+				http.Error(w, "[ERROR]: Unknown operation or negative time: "+key, http.StatusBadRequest)
+				log.Println("[ERROR]: Unknown operation or negative time: " + key)
+				return
+			}
 		}
-
-		div, err := strconv.Atoi(message.DivisionTime)
-		if err != nil {
-			http.Error(w, "[ERROR]: Convert string to int was failed: "+err.Error(), http.StatusBadRequest)
-			log.Println("[ERROR]: Convert string to int was failed: " + err.Error())
-			return
-		}
-
-		mul, err := strconv.Atoi(message.MultiplicationTime)
-		if err != nil {
-			http.Error(w, "[ERROR]: Convert string to int was failed: "+err.Error(), http.StatusBadRequest)
-			log.Println("[ERROR]: Convert string to int was failed: " + err.Error())
-			return
-		}
-
-		request := TimeOfOperationJSON{
-			map[string]int{
-				"+": add,
-				"-": sub,
-				"/": div,
-				"*": mul,
-			},
-		}
-
 		// Формируем JSON
-		jsonRequest, err := json.Marshal(request)
+		jsonRequest, err := json.Marshal(message)
 		if err != nil {
 			http.Error(w, "[ERROR]: Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
 			log.Println("[ERROR]: Can not encoding to JSON: " + err.Error())
@@ -311,6 +522,48 @@ func (e *SendMessageWithTimeOfOperations) getExecutorHandler() func(w http.Respo
 	}
 }
 
+/*
+GetTimeOfOperationsFromThirdPage принимает запрос и возвращает
+время выполнения всех операций и встроенных функций,
+по которому веб страница строит список настроек
+*/
+type GetTimeOfOperationsFromThirdPage struct{}
+
+func NewGetTimeOfOperationsFromThirdPage() *GetTimeOfOperationsFromThirdPage {
+	return &GetTimeOfOperationsFromThirdPage{}
+}
+
+func (e *GetTimeOfOperationsFromThirdPage) getExecutorRoute() string {
+	return "/getTimeOfOperations"
+}
+
+func (e *GetTimeOfOperationsFromThirdPage) getExecutorHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Пробует отправить запрос на бэк для получения времени операций
+		resp, err := http.Get("http://orchestrator_server:8082/getExecutionTimeOfOperations")
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not send request: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not send request: " + err.Error())
+			return
+		}
+		defer resp.Body.Close()
+
+		// Вытаскиваем тело из ответа, в котором зашифрован JSON
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			http.Error(w, "Error reading response from Server 2", http.StatusInternalServerError)
+			return
+		}
+
+		// Заполняем тело запроса и заголовки
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+
+		log.Println("[OK]: Send time of operations was successful")
+	}
+}
+
 /*
 GetListOfSolversFromFourthPage принимает запрос
 и возвращает список с информацией о вычислителях
@@ -358,3 +611,67 @@ func (e *GetListOfSolversFromFourthPage) getExecutorHandler() func(w http.Respon
 		log.Println("[OK]: Send list of solvers was successful")
 	}
 }
+
+/*
+GetEventsFromAllPages передает веб странице поток событий
+оркестратора об изменении задач и вычислителей, по которым
+вкладки страницы обновляются сразу, а не по таймеру
+*/
+type GetEventsFromAllPages struct{}
+
+func NewGetEventsFromAllPages() *GetEventsFromAllPages {
+	return &GetEventsFromAllPages{}
+}
+
+func (e *GetEventsFromAllPages) getExecutorRoute() string {
+	return "/events"
+}
+
+func (e *GetEventsFromAllPages) getExecutorHandler() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "[ERROR]: Streaming is not supported", http.StatusInternalServerError)
+			log.Println("[ERROR]: Streaming is not supported")
+			return
+		}
+
+		// Запрос к оркестратору живет, пока страница не отключится
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, "http://orchestrator_server:8082/events", nil)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not create request: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: Can not create request: " + err.Error())
+			return
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			http.Error(w, "[ERROR]: Can not subscribe to events: "+err.Error(), http.StatusBadGateway)
+			log.Println("[ERROR]: Can not subscribe to events: " + err.Error())
+			return
+		}
+		defer resp.Body.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(resp.StatusCode)
+		flusher.Flush()
+		log.Println("[OK]: Page subscribed to events")
+
+		// Передаем события странице сразу, как только они пришли
+		buffer := make([]byte, 4096)
+		for {
+			n, err := resp.Body.Read(buffer)
+			if n > 0 {
+				if _, err := w.Write(buffer[:n]); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+			if err != nil {
+				log.Println("[INFO]: Events stream was closed")
+				return
+			}
+		}
+	}
+}
@@ -4,4 +4,21 @@ go 1.20
 
 require github.com/lib/pq v1.10.9
 
-require github.com/Knetic/govaluate v3.0.0+incompatible // indirect
+require (
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
+
+require expression v0.0.0
+
+replace expression => ../expression
+
+require (
+	google.golang.org/grpc v1.64.1
+	solverapi v0.0.0
+)
+
+replace solverapi => ../solverapi
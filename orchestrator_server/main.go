@@ -22,18 +22,29 @@ func main() {
 		APIPort: "8082",
 		APIExecutors: []pkg.Executor{
 			pkg.NewAddArithmeticExpression(menager),
+			pkg.NewAddArithmeticExpressions(menager),
 			pkg.NewGetListExpressionsWithStatuses(menager),
+			pkg.NewTasks(menager),
+			pkg.NewGetListOfTasksFromIDs(menager),
 			pkg.NewSetTimeOfOperations(menager),
+			pkg.NewGetTimeOfOperations(menager),
 			pkg.NewGetReadyTaskToSolving(menager),
 			pkg.NewGetResultOfSolving(menager),
 			pkg.NewGetListOfSolvers(menager),
 			pkg.NewGetHandShake(menager),
+			pkg.NewExplainExpression(menager),
+			pkg.NewLookupSubresult(menager),
+			pkg.NewStoreSubresult(menager),
+			pkg.NewEvents(menager),
 		},
 	}
 
 	// Запускаем апи
 	api.APIRun()
 
+	// Запускаем gRPC сервер для вычислителей
+	pkg.NewSolverServer(menager, "8083").Run()
+
 	// Создаем канал с сигналом об остановки сервиса
 	osSignalsChan := make(chan os.Signal, 1)
 	signal.Notify(osSignalsChan, os.Interrupt)
@@ -1,8 +1,10 @@
 package pkg
 
 import (
+	"expression"
 	"log"
 	"net/http"
+	"os"
 	"sync"
 	"time"
 )
@@ -52,12 +54,39 @@ MessageManager определяет структуру содержащю общ
 
 5. Структура содержит мутекс, для безопасного доступа к словарям
 менеджера во время параллельных запросов
+
+6. Структура содержит словарь с графами операций задач, которые
+считаются вычислителями в режиме операций, ключ словаря номер задачи
+
+7. Структура содержит кэш с результатами подвыражений, которые
+уже посчитали вычислители, ключ кэша это ключ операции с
+операндами, например float:*(12,17). Через него вычислители
+не считают заново одинаковые операции из разных задач
+
+8. Структура содержит подписки клиентов, которые ждут
+завершения задач, о завершении задачи им сообщают исполнители,
+записавшие ее результат
+
+9. Структура содержит шину событий, в которую исполнители и демон
+рукопожатий публикуют изменения задач и вычислителей
+
+10. Структура содержит отправителя результатов завершенных
+задач на адреса клиентов (CallbackURL)
+
+11. Структура содержит сигнал о новой задаче или операции,
+которого ждут запросы вычислителей на получение задачи
 */
 type MessageManager struct {
 	DbConnection     *DatabaseConnection
 	DbLockChan       chan int
 	OperationTimeMap map[string]int
 	SolverInfoMap    map[string]*Solver
+	TaskGraphMap     map[int]*TaskGraph
+	Subresults       *SubresultCache
+	TaskWaiters      *TaskWaiters
+	EventBus         *EventBus
+	Webhooks         *WebhookSender
+	TaskAvailable    *TaskSignal
 	Mutex            sync.Mutex
 }
 
@@ -69,6 +98,11 @@ func NewMessageManager() (*MessageManager, error) {
 	var manager MessageManager
 	manager.OperationTimeMap = make(map[string]int)
 	manager.SolverInfoMap = make(map[string]*Solver)
+	manager.TaskGraphMap = make(map[int]*TaskGraph)
+	manager.Subresults = NewSubresultCache(maxSubresults)
+	manager.TaskWaiters = NewTaskWaiters()
+	manager.EventBus = NewEventBus()
+	manager.TaskAvailable = NewTaskSignal()
 	manager.DbLockChan = make(chan int, 1)
 
 	// Создаем коннект к базе данных
@@ -82,41 +116,70 @@ func NewMessageManager() (*MessageManager, error) {
 	// Кладем ссылку на соединение в менеджер
 	manager.DbConnection = dbConn
 
+	// Запускаем отправку результатов задач на адреса клиентов,
+	// в том числе недоставленных до перезапуска оркестратора
+	manager.Webhooks = NewWebhookSender(dbConn, os.Getenv("WEBHOOK_SECRET"), os.Getenv("WEBHOOK_ALLOW_HOSTS"))
+	go manager.Webhooks.Run()
+
+	// Заполняем словарь значениями по умолчанию, что бы у операций
+	// и функций, которых еще нет в базе данных, тоже было время
+	manager.SetDefaultTimesOfOperation()
+
 	// Пробуем получить настройки времени выполнения операций из базы данных
 	timesOfOperation, err := manager.DbConnection.GetAllTimesOfOperation()
-	isCorrect := true
 	if err != nil {
 		log.Println("[ERROR]: Can not get settings from operation_table")
-		// Если не удалось, то заполняем значениями по умолчанию
-		manager.SetDefaultTimesOfOperation()
 	} else {
-		// Проверяем корректность полученных данных из базы данных
-		if len(timesOfOperation) > 4 {
-			isCorrect = false
-		}
+		// Проверяем полученные данные по таблице операций выражения:
+		// время неизвестной операции пропускаем, а известной
+		// записываем в словарь со временем выпонения операций
+		stored := make(map[string]bool)
 		for _, val := range timesOfOperation {
-			if val.Operation != "+" && val.Operation != "-" && val.Operation != "/" && val.Operation != "*" {
-				isCorrect = false
-				break
+			if !expression.IsOperation(val.Operation) || val.TimeOfOperation < 0 {
+				log.Println("[ERROR]: Incorrect setting in operation_table: " + val.Operation)
+				continue
 			}
+			manager.OperationTimeMap[val.Operation] = val.TimeOfOperation
+			stored[val.Operation] = true
 		}
-	}
 
-	// Если корректно, то заполняем словарь со временем выпонения операций
-	if isCorrect {
-		for _, val := range timesOfOperation {
-			manager.OperationTimeMap[val.Operation] = val.TimeOfOperation
+		// Операции, которых еще нет в базе данных (например
+		// добавленные в новой версии), записываем со временем по умолчанию
+		missing := TimeOfOperationJSON{Times: make(map[string]int)}
+		for _, operation := range expression.Operations() {
+			if !stored[operation] {
+				missing.Times[operation] = manager.OperationTimeMap[operation]
+			}
 		}
+		if err = manager.DbConnection.SetTimesToOperation(missing); err != nil {
+			log.Println("[ERROR]: Can not write default settings to operation_table: " + err.Error())
+		}
+	}
+
+	// Задачи с результатом из кэша, которые ждали предсказанного
+	// времени окончания до перезапуска, завершаем заново
+	if err = manager.ResumeCachedTasks(); err != nil {
+		log.Println("[ERROR]: Can not resume cached tasks: " + err.Error())
 	}
 
-	// Запускаем демон с проверкой разницы во времени рукопожатий сервером
+	// Запускаем демон с проверкой разницы во времени рукопожатий сервером.
+	// Задачи пропавших вычислителей возвращаются в обработку уже без
+	// мутекса, что бы запрос к базе данных не задерживал остальные запросы
 	ticker := time.NewTicker(1 * time.Second)
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
+				released := make(map[string]int)
 				manager.Mutex.Lock()
 				for _, val := range manager.SolverInfoMap {
+					// Сообщаем о пропаже вычислителя один раз,
+					// пока он снова не пришлет рукопожатие
+					if time.Now().Sub(val.LastPing) >= 2*time.Second && !val.lost {
+						val.lost = true
+						manager.EventBus.PublishSolver(EventSolverLost, val.SolverName)
+					}
+
 					// Если рукопожатие нет очень долго
 					if time.Now().Sub(val.LastPing) >= 10*time.Second {
 						val.InfoString = "Solver is died"
@@ -125,18 +188,19 @@ func NewMessageManager() (*MessageManager, error) {
 
 					// Если рукопожатие пропало
 					if time.Now().Sub(val.LastPing) >= 2*time.Second {
-						// Пишем что сервер недоступен
+						// Пишем что сервер недоступен и отдаем
+						// его задачу или операцию другим вычислителям
 						val.InfoString = "The server is not working"
-						// Задачу которую сервер решал, переводим в статус 1 (в обработке)
-						// что бы сделает ее доступной для других вычислителей
-						err = manager.DbConnection.UpdateStatusAndResultFromExpression(
-							1, val.SolvingNowExpression, "")
-						if err != nil {
-							log.Println("[ERROR]: Database error: " + err.Error())
+						if id := manager.releaseSolver(val); id != 0 {
+							released[val.SolverName] = id
 						}
 					}
 				}
 				manager.Mutex.Unlock()
+
+				for solverName, id := range released {
+					manager.requeueTask(solverName, id)
+				}
 			}
 		}
 	}()
@@ -145,15 +209,78 @@ func NewMessageManager() (*MessageManager, error) {
 	return &manager, nil
 }
 
+/*
+releaseSolver возвращает операцию, которую считал вычислитель,
+в число готовых к выдаче, а задачу отнимает у вычислителя.
+Вызывается под мутексом менеджера, когда вычислитель пропал.
+Задачу в базе данных нужно вернуть в обработку через requeueTask
+уже после того, как мутекс отпущен
+
+Returns:
+
+	int: Номер задачи, которую нужно вернуть в обработку, или 0
+*/
+func (manager *MessageManager) releaseSolver(solver *Solver) int {
+	if solver == nil {
+		return 0
+	}
+
+	// Если вычислитель считал одну операцию графа задачи,
+	// то возвращаем ее в число готовых к выдаче
+	if solver.operation != nil {
+		solver.graph.Release(solver.operation)
+		manager.TaskAvailable.Broadcast()
+		solver.graph = nil
+		solver.operation = nil
+		solver.SolvingNowExpression = "None"
+		return 0
+	}
+
+	id := solver.taskID
+	solver.taskID = 0
+	solver.SolvingNowExpression = "None"
+	return id
+}
+
+/*
+requeueTask переводит задачу, которую отняли у вычислителя, в статус 1
+(в обработке), что бы сделать ее доступной для других вычислителей.
+Вызывается без мутекса менеджера. Если база данных недоступна, то задача
+снова записывается за вычислителем, если он еще свободен, и демон
+рукопожатий попробует вернуть ее в обработку еще раз
+
+Parameters:
+
+	string: Имя вычислителя
+	int: Номер задачи
+*/
+func (manager *MessageManager) requeueTask(solverName string, id int) {
+	if id == 0 {
+		return
+	}
+
+	err := manager.DbConnection.UpdateStatusAndResultFromID(1, id, "")
+	if err != nil {
+		log.Println("[ERROR]: Database error: " + err.Error())
+		manager.Mutex.Lock()
+		solver, ok := manager.SolverInfoMap[solverName]
+		if ok && solver.taskID == 0 && solver.operation == nil {
+			solver.taskID = id
+		}
+		manager.Mutex.Unlock()
+		return
+	}
+	manager.TaskAvailable.Broadcast()
+}
+
 /*
 SetDefaultTimesOfOperation заполняет словарь со временем выполнения
-операций настройками по умолчанию
+операций и встроенных функций настройками по умолчанию
 */
 func (manager *MessageManager) SetDefaultTimesOfOperation() {
-	manager.OperationTimeMap["+"] = 1
-	manager.OperationTimeMap["-"] = 1
-	manager.OperationTimeMap["/"] = 1
-	manager.OperationTimeMap["*"] = 1
+	for operation, seconds := range expression.DefaultTimes() {
+		manager.OperationTimeMap[operation] = seconds
+	}
 }
 
 /*
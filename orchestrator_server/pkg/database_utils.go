@@ -2,12 +2,17 @@ package pkg
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	//"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type SettingsTimeOfOperation struct {
@@ -16,6 +21,67 @@ type SettingsTimeOfOperation struct {
 	TimeOfOperation int
 }
 
+/*
+WebhookDelivery описывает отправку результата задачи на адрес
+клиента из таблицы webhook_table: тело запроса, статус отправки,
+количество сделанных попыток и время следующей попытки
+*/
+type WebhookDelivery struct {
+	ID          int
+	TaskID      int
+	URL         string
+	Payload     string
+	Status      int
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+}
+
+/*
+TaskFilter описывает выборку задач для списка задач: статусы,
+промежуток времени создания задачи, подстроку выражения, поле
+сортировки и ее направление, количество задач и курсор, после
+которого нужно продолжить выборку. Пустые поля не ограничивают выборку
+*/
+type TaskFilter struct {
+	Statuses   []int
+	From       time.Time
+	To         time.Time
+	Expression string
+	Sort       string
+	Descending bool
+	Limit      int
+	Cursor     *TaskCursor
+}
+
+/*
+TaskCursor указывает на последнюю задачу предыдущей страницы
+списка: значение поля сортировки и номер задачи
+*/
+type TaskCursor struct {
+	Value string `json:"value"`
+	ID    int    `json:"id"`
+}
+
+/*
+taskSortColumns содержит поля, по которым можно сортировать
+список задач, колонку таблицы и тип значения для курсора
+*/
+var taskSortColumns = map[string][2]string{
+	"id":        {"id", "bigint"},
+	"status":    {"status", "bigint"},
+	"beginTime": {"time_begin", "timestamp"},
+	"endTime":   {"time_end", "timestamp"},
+}
+
+/*
+IsTaskSortField проверяет, что по полю можно сортировать список задач
+*/
+func IsTaskSortField(field string) bool {
+	_, ok := taskSortColumns[field]
+	return ok
+}
+
 type DatabaseConnection struct {
 	DB *sql.DB
 }
@@ -53,7 +119,7 @@ func NewDatabaseConnection(connectString string) (*DatabaseConnection, error) {
         expression VARCHAR(255), 
         hash VARCHAR(255), 
         status BIGINT,
-		result VARCHAR(255),
+		result TEXT,
 		time_begin TIMESTAMP,
 		time_end TIMESTAMP
     );`)
@@ -64,6 +130,81 @@ func NewDatabaseConnection(connectString string) (*DatabaseConnection, error) {
 		return databaseConnection, err
 	}
 
+	// В таблице, созданной в старых версиях оркестратора,
+	// добавляем колонки для фактического времени окончания
+	// вычисления, режима вычисления, десятичной записи результата,
+	// значений переменных выражения (хранятся в виде JSON),
+	// признака того, что результат взят из кэша, ключа
+	// идемпотентности, с которым клиент отправил задачу,
+	// и адреса, на который отправляется результат задачи
+	_, err = db.Exec(`
+    ALTER TABLE task_table
+        ADD COLUMN IF NOT EXISTS time_actual TIMESTAMP DEFAULT '0001-01-01 00:00:00',
+        ADD COLUMN IF NOT EXISTS numeric_mode VARCHAR(16) DEFAULT 'float',
+        ADD COLUMN IF NOT EXISTS decimal_precision INT DEFAULT 10,
+        ADD COLUMN IF NOT EXISTS result_decimal TEXT DEFAULT '',
+        ADD COLUMN IF NOT EXISTS variables TEXT DEFAULT '{}',
+        ADD COLUMN IF NOT EXISTS cached BOOLEAN DEFAULT false,
+        ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255) DEFAULT '',
+        ADD COLUMN IF NOT EXISTS callback_url VARCHAR(2048) DEFAULT '',
+        ADD COLUMN IF NOT EXISTS simulate_cached_time BOOLEAN DEFAULT false;`)
+	if err != nil {
+		return databaseConnection, err
+	}
+
+	// Точный результат может быть длиннее 255 символов, поэтому
+	// в таблице из старых версий оркестратора меняем тип колонок
+	_, err = db.Exec(`
+    ALTER TABLE task_table
+        ALTER COLUMN result TYPE TEXT,
+        ALTER COLUMN result_decimal TYPE TEXT;`)
+	if err != nil {
+		return databaseConnection, err
+	}
+
+	// По хэшу задачи ищутся уже посчитанные результаты, поэтому индексируем его
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS task_table_hash_idx ON task_table (hash);`)
+	if err != nil {
+		return databaseConnection, err
+	}
+
+	// Список задач фильтруется по статусу и сортируется
+	// по времени создания, поэтому индексируем эти колонки
+	_, err = db.Exec(`
+    CREATE INDEX IF NOT EXISTS task_table_status_idx ON task_table (status, id);
+    CREATE INDEX IF NOT EXISTS task_table_time_begin_idx ON task_table (time_begin, id);`)
+	if err != nil {
+		return databaseConnection, err
+	}
+
+	// Ключ идемпотентности уникален, поэтому при одновременных повторах
+	// одного запроса в таблицу попадет только одна задача
+	_, err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS task_table_idempotency_key_idx
+		ON task_table (idempotency_key) WHERE idempotency_key <> '';`)
+	if err != nil {
+		return databaseConnection, err
+	}
+
+	// Если по какой то причине в базе нет таблицы с отправками
+	// результатов задач на адреса клиентов, то создаем таблицу.
+	// Статус отправки: 1 ждет отправки, 2 доставлена, 3 не доставлена
+	// за все попытки. next_attempt это время следующей попытки
+	_, err = db.Exec(`
+    CREATE TABLE IF NOT EXISTS webhook_table (
+        id integer PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+        task_id integer,
+        url VARCHAR(2048),
+        payload TEXT,
+        status BIGINT,
+        attempts INT DEFAULT 0,
+        next_attempt TIMESTAMP,
+        last_error TEXT DEFAULT ''
+    );
+    CREATE INDEX IF NOT EXISTS webhook_table_status_idx ON webhook_table (status, next_attempt);`)
+	if err != nil {
+		return databaseConnection, err
+	}
+
 	// Если по какой то причине в базе нет таблицы с настройками
 	// времени вычисленя, то создаем таблицу
 	_, err = db.Exec(`
@@ -107,30 +248,97 @@ func (db *DatabaseConnection) CloseConnecton() error {
 }
 
 /*
-AddTask записывает задачу в базу данных
+AddTask записывает задачу в базу данных и возвращает ее номер
+*/
+func (db *DatabaseConnection) AddTask(task TaskJSON) (int, error) {
+	return insertTask(db.DB, task)
+}
+
+/*
+AddTasks записывает задачи в базу данных одной транзакцией
+и возвращает их номера в том же порядке. Если хотя бы одну
+задачу записать не удалось, то не записывается ни одна
+*/
+func (db *DatabaseConnection) AddTasks(tasks []TaskJSON) ([]int, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		id, err := insertTask(tx, task)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+/*
+rowQuerier это общий метод соединения с базой данных и транзакции,
+который нужен для записи задачи
 */
-func (db *DatabaseConnection) AddTask(task TaskJSON) error {
-	_, err := db.DB.Exec(`INSERT INTO task_table (
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+/*
+insertTask записывает задачу через соединение или транзакцию
+и возвращает номер задачи
+*/
+func insertTask(db rowQuerier, task TaskJSON) (int, error) {
+	variables, err := json.Marshal(task.Variables)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = db.QueryRow(`INSERT INTO task_table (
 		expression, 
         hash, 
         status,
 		result,
 		time_begin,
-		time_end
-		) VALUES ($1, $2, $3, $4, $5, $6)`,
+		time_end,
+		time_actual,
+		numeric_mode,
+		decimal_precision,
+		result_decimal,
+		variables,
+		cached,
+		idempotency_key,
+		callback_url,
+		simulate_cached_time
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`,
 		task.Expression,
 		task.HashID,
 		task.Status,
 		task.Result,
-		task.BeginTime.Format("2006-01-02 15:04:05"),
-		task.EndTime.Format("2006-01-02 15:04:05"),
-	)
+		dbTime(task.BeginTime),
+		dbTime(task.EndTime),
+		dbTime(task.ActualEndTime),
+		task.NumericMode,
+		task.Precision,
+		task.ResultDecimal,
+		string(variables),
+		task.Cached,
+		task.IdempotencyKey,
+		task.CallbackURL,
+		task.SimulateCachedTime,
+	).Scan(&id)
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return id, nil
 }
 
 /*
@@ -142,10 +350,133 @@ func (db *DatabaseConnection) GetAllTasks() ([]TaskJSON, error) {
 		return nil, err
 	}
 
+	return scanTasks(rows)
+}
+
+/*
+GetTasksFromFilter возвращает одну страницу списка задач,
+подходящих под фильтр, отсортированную по полю фильтра,
+а при равных значениях по номеру задачи. Что бы узнать,
+есть ли следующая страница, запрашивается на одну задачу больше
+
+Returns:
+
+	[]TaskJSON: Задачи страницы
+	*TaskCursor: Курсор следующей страницы или nil, если это последняя страница
+	error: Ошибка базы данных
+*/
+func (db *DatabaseConnection) GetTasksFromFilter(filter TaskFilter) ([]TaskJSON, *TaskCursor, error) {
+	sort, ok := taskSortColumns[filter.Sort]
+	if !ok {
+		sort = taskSortColumns["id"]
+	}
+	column, columnType := sort[0], sort[1]
+
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]int64, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, int64(status))
+		}
+		conditions = append(conditions, "status = ANY("+arg(pq.Array(statuses))+")")
+	}
+	// Время хранится в UTC без часового пояса,
+	// поэтому границы промежутка тоже переводятся в UTC
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "time_begin >= "+arg(dbTime(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "time_begin < "+arg(dbTime(filter.To)))
+	}
+	if filter.Expression != "" {
+		// Знаки шаблона в подстроке ищем как обычные символы
+		pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(filter.Expression)
+		conditions = append(conditions, "expression LIKE "+arg("%"+pattern+"%"))
+	}
+
+	// Продолжаем выборку после последней задачи предыдущей страницы
+	order, compare := "ASC", ">"
+	if filter.Descending {
+		order, compare = "DESC", "<"
+	}
+	if filter.Cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(%v, id) %v (%v::%v, %v)",
+			column, compare, arg(filter.Cursor.Value), columnType, arg(filter.Cursor.ID)))
+	}
+
+	query := "SELECT * FROM task_table"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %v %v, id %v LIMIT %v", column, order, order, arg(filter.Limit+1))
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks, err := scanTasks(rows)
+	if err != nil || len(tasks) <= filter.Limit {
+		return tasks, nil, err
+	}
+
+	// Задач больше, чем помещается на страницу, значит
+	// курсор следующей страницы указывает на последнюю задачу
+	tasks = tasks[:filter.Limit]
+	last := tasks[len(tasks)-1]
+	cursor := &TaskCursor{ID: last.ID}
+	switch column {
+	case "id":
+		cursor.Value = strconv.Itoa(last.ID)
+	case "status":
+		cursor.Value = strconv.Itoa(last.Status)
+	case "time_begin":
+		cursor.Value = dbTime(last.BeginTime)
+	case "time_end":
+		cursor.Value = dbTime(last.EndTime)
+	}
+	return tasks, cursor, nil
+}
+
+/*
+timestampLayout формат, в котором время передается в запросы к базе данных
+*/
+const timestampLayout = "2006-01-02 15:04:05.999999"
+
+/*
+dbTime переводит время в UTC и записывает его в формате timestampLayout.
+Колонки времени хранят время без часового пояса, поэтому любое время
+передается в запросы к базе данных только через эту функцию
+*/
+func dbTime(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+/*
+scanTasks читает задачи из результата запроса к task_table
+*/
+func scanTasks(rows *sql.Rows) ([]TaskJSON, error) {
+	defer rows.Close()
+
 	tasks := make([]TaskJSON, 0)
 	for rows.Next() {
 		var t TaskJSON
-		err = rows.Scan(&t.ID, &t.Expression, &t.HashID, &t.Status, &t.Result, &t.BeginTime, &t.EndTime)
+		var variables string
+		err := rows.Scan(&t.ID, &t.Expression, &t.HashID, &t.Status, &t.Result,
+			&t.BeginTime, &t.EndTime, &t.ActualEndTime,
+			&t.NumericMode, &t.Precision, &t.ResultDecimal, &variables, &t.Cached,
+			&t.IdempotencyKey, &t.CallbackURL, &t.SimulateCachedTime)
+		if err != nil {
+			return nil, err
+		}
+
+		// Значения переменных хранятся в таблице в виде JSON
+		err = json.Unmarshal([]byte(variables), &t.Variables)
 		if err != nil {
 			return nil, err
 		}
@@ -153,7 +484,7 @@ func (db *DatabaseConnection) GetAllTasks() ([]TaskJSON, error) {
 		tasks = append(tasks, t)
 	}
 
-	return tasks, nil
+	return tasks, rows.Err()
 }
 
 /*
@@ -165,43 +496,107 @@ func (db *DatabaseConnection) GetTasksFromStatus(status int) ([]TaskJSON, error)
 		return nil, err
 	}
 
-	tasks := make([]TaskJSON, 0)
-	for rows.Next() {
-		var t TaskJSON
-		err = rows.Scan(&t.ID, &t.Expression, &t.HashID, &t.Status, &t.Result, &t.BeginTime, &t.EndTime)
-		if err != nil {
-			return nil, err
-		}
+	return scanTasks(rows)
+}
 
-		tasks = append(tasks, t)
+/*
+Методы обновления задачи по номеру не трогают отмененные задачи
+(статус 5), что бы ответ вычислителя, пришедший после отмены,
+не перезаписал ее
+*/
+
+/*
+UpdateStatusFromID обновляет статус у задачи с определенным номером
+*/
+func (db *DatabaseConnection) UpdateStatusFromID(status int, id int) error {
+	_, err := db.DB.Exec("UPDATE task_table SET status = $1 WHERE id = $2 AND status <> 5", status, id)
+	return err
+}
+
+/*
+DispatchTaskFromID переводит задачу с определенным номером из статуса 1
+(принята в обработку) в статус 2 (отдана вычислителю) и записывает
+предсказанное время окончания ее выполнения. Задачу, которую успели
+отменить или выдать, не трогает
+
+Returns:
+
+	bool: true, если задача переведена в статус 2
+	error: Ошибка базы данных
+*/
+func (db *DatabaseConnection) DispatchTaskFromID(timeEnd time.Time, id int) (bool, error) {
+	res, err := db.DB.Exec("UPDATE task_table SET status = 2, time_end = $2 WHERE id = $1 AND status = 1",
+		id, dbTime(timeEnd))
+	if err != nil {
+		return false, err
 	}
 
-	return tasks, nil
+	count, err := res.RowsAffected()
+	return count > 0, err
 }
 
 /*
-UpdateStatusFromExpression обновляет статус у записи с определенным выражением
+UpdateStatusAndResultFromID обновляет статус и результат у задачи с определенным номером
 */
-func (db *DatabaseConnection) UpdateStatusFromExpression(status int, expression string) error {
-	_, err := db.DB.Exec("UPDATE task_table SET status = $1 WHERE expression = $2", status, expression)
+func (db *DatabaseConnection) UpdateStatusAndResultFromID(status int, id int, result string) error {
+	_, err := db.DB.Exec("UPDATE task_table SET status = $1, result = $3 WHERE id = $2 AND status <> 5",
+		status, id, result)
 	return err
 }
 
 /*
-UpdateStatusAndTimeFromExpression обновляет статус и время окончания выполнения у задачи
+FinishTaskFromID записывает итоговый статус и результат задачи
+с определенным номером, десятичную запись результата (в точном режиме
+вычисления), а так же фактическое время окончания ее вычисления.
+Завершить можно только задачу, отданную вычислителю (статус 2)
+
+Returns:
+
+	bool: true, если задача была завершена
+	error: Ошибка базы данных
 */
-func (db *DatabaseConnection) UpdateStatusAndTimeFromExpression(timeEnd time.Time, status int, expression string) error {
-	_, err := db.DB.Exec("UPDATE task_table SET status = $1, time_end = $3 WHERE expression = $2", status, expression, timeEnd)
-	return err
+func (db *DatabaseConnection) FinishTaskFromID(status int, id int, result string,
+	resultDecimal string, timeActual time.Time) (bool, error) {
+	res, err := db.DB.Exec(`UPDATE task_table SET status = $1, result = $3, result_decimal = $4, time_actual = $5
+		WHERE id = $2 AND status = 2`,
+		status, id, result, resultDecimal, dbTime(timeActual))
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+	return count > 0, err
 }
 
 /*
-UpdateStatusFromExpression обновляет статус и результат у записи с определенным выражением
+isDataError возвращает true, если база данных отклонила значение
+из запроса (например, оно не помещается в колонку). Повторять
+такой запрос бессмысленно, в отличие от ошибки соединения
 */
-func (db *DatabaseConnection) UpdateStatusAndResultFromExpression(status int, expression string, result string) error {
-	_, err := db.DB.Exec("UPDATE task_table SET status = $1, result = $3 WHERE expression = $2", 
-						status, expression, result)
-	return err
+func isDataError(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Class() == "22"
+}
+
+/*
+CancelTaskFromID переводит задачу с определенным номером в статус 5
+(отменена), если она еще не посчитана, то есть имеет статус 1 или 2
+
+Returns:
+
+	bool: true, если задача была отменена
+	error: Ошибка базы данных
+*/
+func (db *DatabaseConnection) CancelTaskFromID(id int, timeActual time.Time) (bool, error) {
+	res, err := db.DB.Exec(`UPDATE task_table SET status = 5, result = 'cancelled', time_actual = $2
+		WHERE id = $1 AND status IN (1, 2)`,
+		id, dbTime(timeActual))
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+	return count > 0, err
 }
 
 /*
@@ -213,26 +608,89 @@ func (db *DatabaseConnection) DeleteTasksFromStatus(status int) error {
 }
 
 /*
-GetTasksFromExpession возвращает задачи с определенным математическим выражением
+GetCompletedTaskFromHash возвращает самую раннюю успешно посчитанную
+задачу с определенным хэшем, то есть с тем же выражением, режимом
+вычисления, переменными и настройками времени выполнения операций.
+Если такой задачи нет, то возвращается пустой список
 */
-func (db *DatabaseConnection) GetTasksFromExpession(expression string) ([]TaskJSON, error) {
-	rows, err := db.DB.Query("SELECT * FROM task_table WHERE expression=$1", expression)
+func (db *DatabaseConnection) GetCompletedTaskFromHash(hash string) ([]TaskJSON, error) {
+	rows, err := db.DB.Query("SELECT * FROM task_table WHERE hash=$1 AND status=3 ORDER BY id LIMIT 1", hash)
 	if err != nil {
 		return nil, err
 	}
 
-	tasks := make([]TaskJSON, 0)
-	for rows.Next() {
-		var t TaskJSON
-		err = rows.Scan(&t.ID, &t.Expression, &t.HashID, &t.Status, &t.Result, &t.BeginTime, &t.EndTime)
-		if err != nil {
-			return nil, err
-		}
+	return scanTasks(rows)
+}
 
-		tasks = append(tasks, t)
+/*
+GetTaskFromID возвращает задачу с определенным номером.
+Если такой задачи нет, то возвращается ошибка sql.ErrNoRows
+*/
+func (db *DatabaseConnection) GetTaskFromID(id int) (TaskJSON, error) {
+	rows, err := db.DB.Query("SELECT * FROM task_table WHERE id=$1", id)
+	if err != nil {
+		return TaskJSON{}, err
 	}
 
-	return tasks, nil
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return TaskJSON{}, err
+	}
+	if len(tasks) == 0 {
+		return TaskJSON{}, sql.ErrNoRows
+	}
+	return tasks[0], nil
+}
+
+/*
+GetTasksFromIDs возвращает задачи с номерами из списка
+в порядке возрастания номеров
+*/
+func (db *DatabaseConnection) GetTasksFromIDs(ids []int) ([]TaskJSON, error) {
+	array := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		array = append(array, int64(id))
+	}
+
+	rows, err := db.DB.Query("SELECT * FROM task_table WHERE id = ANY($1) ORDER BY id", pq.Array(array))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTasks(rows)
+}
+
+/*
+GetTaskFromIdempotencyKey возвращает задачу, отправленную
+с определенным ключом идемпотентности. Если такой задачи нет,
+то возвращается ошибка sql.ErrNoRows
+*/
+func (db *DatabaseConnection) GetTaskFromIdempotencyKey(key string) (TaskJSON, error) {
+	rows, err := db.DB.Query("SELECT * FROM task_table WHERE idempotency_key=$1", key)
+	if err != nil {
+		return TaskJSON{}, err
+	}
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return TaskJSON{}, err
+	}
+	if len(tasks) == 0 {
+		return TaskJSON{}, sql.ErrNoRows
+	}
+	return tasks[0], nil
+}
+
+/*
+GetTasksFromExpession возвращает задачи с определенным математическим выражением
+*/
+func (db *DatabaseConnection) GetTasksFromExpession(expression string) ([]TaskJSON, error) {
+	rows, err := db.DB.Query("SELECT * FROM task_table WHERE expression=$1", expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTasks(rows)
 }
 
 /*
@@ -292,3 +750,55 @@ func (db *DatabaseConnection) SetTimesToOperation(times TimeOfOperationJSON) err
 	
 	return nil
 }
+
+/*
+AddWebhookDelivery записывает отправку результата задачи на адрес
+клиента со статусом 1 (ждет отправки) и первой попыткой сразу
+*/
+func (db *DatabaseConnection) AddWebhookDelivery(taskID int, url string, payload string) (int, error) {
+	var id int
+	err := db.DB.QueryRow(`INSERT INTO webhook_table (task_id, url, payload, status, attempts, next_attempt)
+		VALUES ($1, $2, $3, 1, 0, $4) RETURNING id`,
+		taskID, url, payload, dbTime(time.Now())).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+/*
+GetDueWebhookDeliveries возвращает отправки, которые ждут отправки
+и время следующей попытки которых уже наступило
+*/
+func (db *DatabaseConnection) GetDueWebhookDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	rows, err := db.DB.Query(`SELECT id, task_id, url, payload, status, attempts, next_attempt, last_error
+		FROM webhook_table WHERE status = 1 AND next_attempt <= $1 ORDER BY next_attempt LIMIT $2`,
+		dbTime(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+	for rows.Next() {
+		var d WebhookDelivery
+		err = rows.Scan(&d.ID, &d.TaskID, &d.URL, &d.Payload, &d.Status, &d.Attempts, &d.NextAttempt, &d.LastError)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+/*
+UpdateWebhookDelivery записывает результат попытки отправки:
+статус, количество попыток, время следующей попытки и ошибку
+*/
+func (db *DatabaseConnection) UpdateWebhookDelivery(delivery WebhookDelivery) error {
+	_, err := db.DB.Exec(`UPDATE webhook_table SET status = $2, attempts = $3, next_attempt = $4, last_error = $5
+		WHERE id = $1`,
+		delivery.ID, delivery.Status, delivery.Attempts,
+		dbTime(delivery.NextAttempt), delivery.LastError)
+	return err
+}
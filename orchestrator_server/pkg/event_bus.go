@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+/*
+Типы событий, которые оркестратор отправляет подписчикам
+*/
+const (
+	EventTaskCreated      = "task.created"
+	EventTaskDispatched   = "task.dispatched"
+	EventTaskCompleted    = "task.completed"
+	EventTaskFailed       = "task.failed"
+	EventTaskCancelled    = "task.cancelled"
+	EventSolverRegistered = "solver.registered"
+	EventSolverLost       = "solver.lost"
+)
+
+/*
+eventBufferSize количество событий, которые ждут отправки
+одному подписчику. Если подписчик не успевает их забирать,
+то новые события для него пропускаются, что бы медленный
+клиент не задерживал исполнителей
+*/
+const eventBufferSize = 256
+
+/*
+EventBus рассылает события об изменении задач и вычислителей
+всем подписчикам, например клиентам исполнителя Events.
+Исполнители и демон рукопожатий публикуют события, не дожидаясь
+их отправки, поэтому публиковать можно и под мутексом менеджера
+*/
+type EventBus struct {
+	mutex       sync.Mutex
+	subscribers map[chan EventJSON]bool
+}
+
+/*
+NewEventBus возвращает ссылку на шину событий без подписчиков
+*/
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[chan EventJSON]bool),
+	}
+}
+
+/*
+Subscribe подписывает на все события, опубликованные после подписки.
+Функцию отписки нужно вызвать, когда подписчик отключился
+
+Returns:
+
+	chan EventJSON: Канал с событиями
+	func(): Функция отписки
+*/
+func (bus *EventBus) Subscribe() (chan EventJSON, func()) {
+	events := make(chan EventJSON, eventBufferSize)
+
+	bus.mutex.Lock()
+	bus.subscribers[events] = true
+	bus.mutex.Unlock()
+
+	unsubscribe := func() {
+		bus.mutex.Lock()
+		defer bus.mutex.Unlock()
+		delete(bus.subscribers, events)
+	}
+	return events, unsubscribe
+}
+
+/*
+Publish отправляет событие всем подписчикам. Время события
+выставляется, если оно не задано
+*/
+func (bus *EventBus) Publish(event EventJSON) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	for events := range bus.subscribers {
+		select {
+		case events <- event:
+		default:
+			log.Println("[ERROR]: Subscriber is too slow, event is dropped: " + event.Type)
+		}
+	}
+}
+
+/*
+PublishTask отправляет событие об изменении задачи
+*/
+func (bus *EventBus) PublishTask(eventType string, task TaskJSON) {
+	bus.Publish(EventJSON{
+		Type:       eventType,
+		TaskID:     task.ID,
+		Expression: task.Expression,
+		Status:     task.Status,
+		Result:     task.Result,
+	})
+}
+
+/*
+PublishSolver отправляет событие об изменении вычислителя
+*/
+func (bus *EventBus) PublishSolver(eventType string, solverName string) {
+	bus.Publish(EventJSON{
+		Type:       eventType,
+		SolverName: solverName,
+	})
+}
@@ -1,12 +1,19 @@
 package pkg
 
 import (
+	"database/sql"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"expression"
+	"fmt"
 	"log"
 	"net/http"
-	"time"
-	"strings"
+	"net/url"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 	//"github.com/Knetic/govaluate"
 )
 
@@ -40,23 +47,752 @@ func (e *AddArithmeticExpression) getExecutorHandler() func(http.ResponseWriter,
 			return
 		}
 
-		task := TaskJSON{
-			ID:         0,
-			Expression: message.Expression,
-			HashID:     "hash",
-			Status:     1,
-			Result:     "",
-			BeginTime:  message.TimeToSend,
-			//EndTime:    message.TimeToSend.Add(e.findExecutionTime(message.Expression)),
-			//EndTime:    ,
+		// Проверяем валидность выражения, если выражение не разбирается
+		// или для какой то переменной не передано значение,
+		// то возвращаем позицию и причину ошибки
+		err = e.Manager.checkExpressionRequest(&message)
+		if err != nil {
+			writeValidationError(w, err)
+			log.Println("[ERROR]: AddArithmeticExpression Can not parse expression: " + err.Error())
+			return
 		}
 
-		e.Manager.DbConnection.AddTask(task)
-		w.WriteHeader(http.StatusOK)
+		// Ключ идемпотентности можно передать заголовком или полем JSON.
+		// Если задача с таким ключом уже есть, то возвращаем ее
+		message.IdempotencyKey, err = idempotencyKey(r.Header.Get("Idempotency-Key"), message.IdempotencyKey)
+		if err != nil {
+			http.Error(w, "[ERROR]: AddArithmeticExpression "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: AddArithmeticExpression " + err.Error())
+			return
+		}
+		if message.IdempotencyKey != "" && e.replayTask(w, message) {
+			return
+		}
+
+		task, err := e.Manager.newTask(message)
+		if err != nil {
+			writeValidationError(w, err)
+			log.Println("[ERROR]: AddArithmeticExpression Can not parse expression: " + err.Error())
+			return
+		}
+
+		task.ID, err = e.Manager.DbConnection.AddTask(task)
+		if err != nil {
+			// Такой же запрос с тем же ключом мог прийти одновременно
+			// с этим и успеть записать задачу первым
+			if message.IdempotencyKey != "" && e.replayTask(w, message) {
+				return
+			}
+			http.Error(w, "[ERROR]: AddArithmeticExpression Can not write task to database: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: AddArithmeticExpression Can not write task to database: " + err.Error())
+			return
+		}
+		e.Manager.publishTaskCreated(task)
+		if task.Cached && message.SimulateCachedTime {
+			e.Manager.FinishCachedTask(task, task.EndTime.Sub(time.Now()))
+		}
+
+		// Возвращаем клиенту номер задачи
+		writeTaskID(w, task.ID)
+		if task.Cached {
+			log.Println("[OK]: Write cached task to database was successful")
+			return
+		}
 		log.Println("[OK]: Write task to database was successful")
 	}
 }
 
+/*
+maxExpressionLength наибольшая длина выражения в запросе,
+она совпадает с размером колонки expression в task_table
+*/
+const maxExpressionLength = 255
+
+/*
+checkExpressionLength возвращает ошибку, если выражение
+длиннее maxExpressionLength байт
+*/
+func checkExpressionLength(expr string) error {
+	if len(expr) > maxExpressionLength {
+		return fmt.Errorf("Expression must be at most %v characters long", maxExpressionLength)
+	}
+	return nil
+}
+
+/*
+checkExpressionRequest проверяет длину выражения, само выражение,
+его переменные, режим вычисления и точность десятичной записи,
+а так же выставляет режим и точность по умолчанию, если они не заданы
+*/
+func (manager *MessageManager) checkExpressionRequest(message *ExpressionRequestJSON) error {
+	err := checkExpressionLength(message.Expression)
+	if err != nil {
+		return err
+	}
+
+	err = expression.Validate(message.Expression, message.Variables)
+	if err != nil {
+		return err
+	}
+
+	if !expression.IsValidMode(message.NumericMode) {
+		return errors.New("Unknown numeric mode: " + message.NumericMode)
+	}
+	if message.Precision < 0 || message.Precision > expression.MaxPrecision {
+		return fmt.Errorf("Precision must be from 0 to %v", expression.MaxPrecision)
+	}
+	if message.CallbackURL != "" {
+		if err = manager.Webhooks.ValidateCallbackURL(message.CallbackURL); err != nil {
+			return err
+		}
+	}
+	if message.NumericMode == "" {
+		message.NumericMode = expression.FloatMode
+	}
+	if message.Precision == 0 {
+		message.Precision = expression.DefaultPrecision
+	}
+	return nil
+}
+
+/*
+newTask создает задачу из проверенного запроса. Если такое же
+выражение уже было посчитано в том же режиме с теми же значениями
+переменных, то задача сразу получает результат из кэша
+*/
+func (manager *MessageManager) newTask(message ExpressionRequestJSON) (TaskJSON, error) {
+	hash, err := ResultCacheHash(message.Expression, message.NumericMode, message.Variables)
+	if err != nil {
+		return TaskJSON{}, err
+	}
+
+	task := TaskJSON{
+		ID:                 0,
+		Expression:         message.Expression,
+		HashID:             hash,
+		Status:             1,
+		Result:             "",
+		BeginTime:          message.TimeToSend,
+		NumericMode:        message.NumericMode,
+		Precision:          message.Precision,
+		Variables:          message.Variables,
+		IdempotencyKey:     message.IdempotencyKey,
+		CallbackURL:        message.CallbackURL,
+		SimulateCachedTime: message.SimulateCachedTime,
+	}
+
+	// Ищем такое же уже посчитанное выражение и берем его результат.
+	// Десятичная запись точного результата считается заново,
+	// потому что точность задачи могла быть другой
+	cachedTasks, err := manager.DbConnection.GetCompletedTaskFromHash(hash)
+	if err != nil {
+		log.Println("[ERROR]: Can not read result cache: " + err.Error())
+	}
+	var cached expression.Number
+	if len(cachedTasks) > 0 {
+		cached, err = expression.ParseNumber(cachedTasks[0].Result, message.NumericMode)
+		if err != nil {
+			log.Println("[ERROR]: Invalid result in result cache: " + err.Error())
+			cachedTasks = nil
+		}
+	}
+	if len(cachedTasks) > 0 {
+		task.Result = cachedTasks[0].Result
+		if message.NumericMode == expression.RationalMode {
+			task.ResultDecimal = cached.Decimal(message.Precision)
+		}
+		task.Cached = true
+		task.Status = 3
+		task.EndTime = time.Now()
+		task.ActualEndTime = task.EndTime
+
+		// Если нужно имитировать вычисление, то задача
+		// считается выданной до предсказанного времени окончания
+		if message.SimulateCachedTime {
+			task.Status = 2
+			task.EndTime = time.Now().Add(manager.PredictExecutionTime(message.Expression))
+			task.ActualEndTime = time.Time{}
+		}
+	}
+	return task, nil
+}
+
+/*
+publishTaskCreated сообщает подписчикам о новой задаче, а вычислителям,
+которые ждут задачу, о том, что она появилась. Задача, которая сразу
+получила результат из кэша, тут же и посчитана, поэтому ее результат
+сразу отправляется на адрес клиента
+*/
+func (manager *MessageManager) publishTaskCreated(task TaskJSON) {
+	manager.EventBus.PublishTask(EventTaskCreated, task)
+	if task.Status == 1 {
+		manager.TaskAvailable.Broadcast()
+	}
+	if task.Status == 3 {
+		manager.EventBus.PublishTask(EventTaskCompleted, task)
+		manager.Webhooks.Enqueue(EventTaskCompleted, task)
+	}
+}
+
+/*
+idempotencyKey возвращает ключ идемпотентности запроса из заголовка
+Idempotency-Key или из поля JSON. Если ключ передан и там и там,
+то он должен совпадать
+*/
+func idempotencyKey(header string, field string) (string, error) {
+	header = strings.TrimSpace(header)
+	field = strings.TrimSpace(field)
+	if header != "" && field != "" && header != field {
+		return "", errors.New("Idempotency-Key header and idempotencyKey field are different")
+	}
+	if header == "" {
+		header = field
+	}
+	if len(header) > 255 {
+		return "", errors.New("Idempotency key is too long")
+	}
+	return header, nil
+}
+
+/*
+replayTask ищет задачу, уже созданную с ключом идемпотентности запроса.
+Если задача найдена и запрос тот же, то клиенту возвращается ее номер,
+а если с этим ключом был отправлен другой запрос, то ошибка 409.
+Возвращает true, если ответ клиенту уже отправлен
+*/
+func (e *AddArithmeticExpression) replayTask(w http.ResponseWriter, message ExpressionRequestJSON) bool {
+	id, err := e.Manager.findIdempotentTask(message)
+	if errors.Is(err, errIdempotencyConflict) {
+		http.Error(w, "[ERROR]: AddArithmeticExpression "+err.Error(), http.StatusConflict)
+		log.Println("[ERROR]: AddArithmeticExpression " + err.Error())
+		return true
+	}
+	if err != nil {
+		http.Error(w, "[ERROR]: AddArithmeticExpression Database error: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: AddArithmeticExpression Database error: " + err.Error())
+		return true
+	}
+	if id == 0 {
+		return false
+	}
+
+	w.Header().Set("Idempotent-Replayed", "true")
+	writeTaskID(w, id)
+	log.Printf("[OK]: Task %v was returned by idempotency key", id)
+	return true
+}
+
+/*
+errIdempotencyConflict возвращается, если с ключом идемпотентности
+уже была отправлена задача с другим выражением
+*/
+var errIdempotencyConflict = errors.New("Idempotency key is already used by another request")
+
+/*
+findIdempotentTask возвращает номер задачи, уже созданной с ключом
+идемпотентности запроса, или ноль, если такой задачи нет. Если задача
+создана другим запросом, то возвращается ошибка errIdempotencyConflict
+*/
+func (manager *MessageManager) findIdempotentTask(message ExpressionRequestJSON) (int, error) {
+	task, err := manager.DbConnection.GetTaskFromIdempotencyKey(message.IdempotencyKey)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !sameRequest(task, message) {
+		return 0, errIdempotencyConflict
+	}
+	return task.ID, nil
+}
+
+/*
+sameRequest проверяет, что задача создана таким же запросом: с тем же
+выражением, режимом вычисления, точностью, переменными, адресом клиента
+и имитацией времени вычисления. Время отправки не сравнивается, его
+выставляет фронтенд при каждой отправке, в том числе повторной
+*/
+func sameRequest(task TaskJSON, message ExpressionRequestJSON) bool {
+	if task.Expression != message.Expression || task.NumericMode != message.NumericMode ||
+		task.Precision != message.Precision || len(task.Variables) != len(message.Variables) ||
+		task.CallbackURL != message.CallbackURL ||
+		task.SimulateCachedTime != message.SimulateCachedTime {
+		return false
+	}
+	for name, value := range message.Variables {
+		if stored, ok := task.Variables[name]; !ok || stored != value {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+writeTaskID отправляет клиенту номер задачи в виде JSON
+*/
+func writeTaskID(w http.ResponseWriter, id int) {
+	jsonResponse, err := json.Marshal(TaskIDJSON{ID: id})
+	if err != nil {
+		http.Error(w, "[ERROR]: Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: Can not encoding to JSON: " + err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResponse)
+}
+
+/*
+maxBatchSize ограничивает количество выражений в одном запросе
+исполнителя AddArithmeticExpressions
+*/
+const maxBatchSize = 1000
+
+/*
+AddArithmeticExpressions принимает запрос с массивом выражений
+и записывает все правильные выражения в базу данных одной
+транзакцией. Для каждого выражения возвращает номер задачи
+или ошибку разбора
+*/
+type AddArithmeticExpressions struct {
+	Manager *MessageManager
+}
+
+func NewAddArithmeticExpressions(manager *MessageManager) *AddArithmeticExpressions {
+	return &AddArithmeticExpressions{
+		Manager: manager,
+	}
+}
+
+func (e *AddArithmeticExpressions) getExecutorRoute() string {
+	return "/addArithmeticExpressions"
+}
+
+func (e *AddArithmeticExpressions) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Декодируем тело запроса в массив выражений
+		var messages []ExpressionRequestJSON
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&messages)
+		if err != nil {
+			http.Error(w, "[ERROR]: AddArithmeticExpressions Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: AddArithmeticExpressions Decoding JSON was failed: " + err.Error())
+			return
+		}
+		if len(messages) == 0 || len(messages) > maxBatchSize {
+			http.Error(w, "[ERROR]: AddArithmeticExpressions Batch must contain from 1 to 1000 expressions", http.StatusBadRequest)
+			log.Println("[ERROR]: AddArithmeticExpressions Batch must contain from 1 to 1000 expressions")
+			return
+		}
+
+		// Проверяем каждое выражение, ошибки записываем в ответ
+		// для этого выражения, а правильные выражения готовим к записи
+		results := make([]BatchItemJSON, len(messages))
+		tasks := make([]TaskJSON, 0, len(messages))
+		positions := make([]int, 0, len(messages))
+		keys := make(map[string]bool)
+		for i, message := range messages {
+			results[i].Index = i
+
+			// Ключ идемпотентности у каждого выражения свой,
+			// поэтому в пакетном запросе он передается только полем JSON
+			err = e.Manager.checkExpressionRequest(&message)
+			if err == nil {
+				message.IdempotencyKey, err = idempotencyKey("", message.IdempotencyKey)
+			}
+			if err == nil && message.IdempotencyKey != "" {
+				// Повтор ключа внутри одного запроса не даст записать транзакцию
+				if keys[message.IdempotencyKey] {
+					err = errors.New("Idempotency key is repeated in batch")
+				}
+				keys[message.IdempotencyKey] = true
+			}
+			if err != nil {
+				results[i].Error = validationError(err)
+				continue
+			}
+			messages[i] = message
+
+			// Задачу, уже созданную с этим ключом идемпотентности, не создаем заново
+			if message.IdempotencyKey != "" {
+				id, err := e.Manager.findIdempotentTask(message)
+				if err != nil && !errors.Is(err, errIdempotencyConflict) {
+					http.Error(w, "[ERROR]: AddArithmeticExpressions Database error: "+err.Error(), http.StatusInternalServerError)
+					log.Println("[ERROR]: AddArithmeticExpressions Database error: " + err.Error())
+					return
+				}
+				if err != nil {
+					results[i].Error = validationError(err)
+					continue
+				}
+				if id != 0 {
+					results[i].ID = id
+					continue
+				}
+			}
+
+			task, err := e.Manager.newTask(message)
+			if err != nil {
+				results[i].Error = validationError(err)
+				continue
+			}
+			tasks = append(tasks, task)
+			positions = append(positions, i)
+		}
+
+		// Записываем все задачи одной транзакцией. Такой же запрос
+		// мог прийти одновременно с этим и успеть записать задачи с теми же
+		// ключами идемпотентности, тогда возвращаем их, а остальные
+		// задачи записываем еще раз
+		ids, err := e.Manager.DbConnection.AddTasks(tasks)
+		if err != nil {
+			var replayed bool
+			var replayErr error
+			tasks, positions, replayed, replayErr = e.replayBatch(tasks, positions, messages, results)
+			if replayErr == nil && replayed {
+				ids, err = e.Manager.DbConnection.AddTasks(tasks)
+			}
+		}
+		if err != nil {
+			http.Error(w, "[ERROR]: AddArithmeticExpressions Can not write tasks to database: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: AddArithmeticExpressions Can not write tasks to database: " + err.Error())
+			return
+		}
+		for j, id := range ids {
+			results[positions[j]].ID = id
+			tasks[j].ID = id
+			e.Manager.publishTaskCreated(tasks[j])
+			if tasks[j].Cached && messages[positions[j]].SimulateCachedTime {
+				e.Manager.FinishCachedTask(tasks[j], tasks[j].EndTime.Sub(time.Now()))
+			}
+		}
+
+		// Конвертируем отклик в json-отклик
+		jsonResponse, err := json.Marshal(results)
+		if err != nil {
+			http.Error(w, "[ERROR]: AddArithmeticExpressions Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: AddArithmeticExpressions Can not encoding to JSON: " + err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+
+		log.Printf("[OK]: Write %v of %v tasks to database was successful", len(ids), len(messages))
+	}
+}
+
+/*
+replayBatch ищет задачи пакета с ключами идемпотентности, которые
+успел записать другой запрос, пока этот запрос готовил транзакцию.
+Номера найденных задач или ошибки конфликта ключей записываются
+в ответ, а остальные задачи возвращаются для повторной записи
+
+Returns:
+
+	[]TaskJSON: Задачи, которые еще нужно записать
+	[]int: Номера этих задач в запросе
+	bool: true, если хотя бы одна задача уже записана
+	error: Ошибка базы данных
+*/
+func (e *AddArithmeticExpressions) replayBatch(tasks []TaskJSON, positions []int,
+	messages []ExpressionRequestJSON, results []BatchItemJSON) ([]TaskJSON, []int, bool, error) {
+	remainingTasks := make([]TaskJSON, 0, len(tasks))
+	remainingPositions := make([]int, 0, len(positions))
+	replayed := false
+	for j, task := range tasks {
+		i := positions[j]
+		if task.IdempotencyKey != "" {
+			id, err := e.Manager.findIdempotentTask(messages[i])
+			if err != nil && !errors.Is(err, errIdempotencyConflict) {
+				return nil, nil, false, err
+			}
+			if err != nil {
+				results[i].Error = validationError(err)
+				replayed = true
+				continue
+			}
+			if id != 0 {
+				results[i].ID = id
+				replayed = true
+				continue
+			}
+		}
+		remainingTasks = append(remainingTasks, task)
+		remainingPositions = append(remainingPositions, i)
+	}
+	return remainingTasks, remainingPositions, replayed, nil
+}
+
+/*
+GetListOfTasksFromIDs принимает запрос со списком номеров задач
+и возвращает эти задачи вместе с их статусами. Номера,
+которых нет в базе данных, пропускаются
+*/
+type GetListOfTasksFromIDs struct {
+	Manager *MessageManager
+}
+
+func NewGetListOfTasksFromIDs(manager *MessageManager) *GetListOfTasksFromIDs {
+	return &GetListOfTasksFromIDs{
+		Manager: manager,
+	}
+}
+
+func (e *GetListOfTasksFromIDs) getExecutorRoute() string {
+	return "/getListOfTasksFromIDs"
+}
+
+func (e *GetListOfTasksFromIDs) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Декодируем тело запроса в JSON нужной нам структуры
+		var message TaskIDsJSON
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&message)
+		if err != nil {
+			http.Error(w, "[ERROR]: GetListOfTasksFromIDs Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: GetListOfTasksFromIDs Decoding JSON was failed: " + err.Error())
+			return
+		}
+		if len(message.IDs) > maxBatchSize {
+			http.Error(w, "[ERROR]: GetListOfTasksFromIDs Too many ids", http.StatusBadRequest)
+			log.Println("[ERROR]: GetListOfTasksFromIDs Too many ids")
+			return
+		}
+
+		tasks, err := e.Manager.DbConnection.GetTasksFromIDs(message.IDs)
+		if err != nil {
+			http.Error(w, "[ERROR]: GetListOfTasksFromIDs Database error: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: GetListOfTasksFromIDs Database error: " + err.Error())
+			return
+		}
+
+		// Конвертируем отклик в json-отклик
+		jsonResponse, err := json.Marshal(tasks)
+		if err != nil {
+			http.Error(w, "[ERROR]: GetListOfTasksFromIDs Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: GetListOfTasksFromIDs Can not encoding to JSON: " + err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+
+		log.Println("[OK]: Send tasks list was successful")
+	}
+}
+
+/*
+Tasks принимает запросы к одной задаче по ее номеру:
+GET /tasks/{id} возвращает задачу, GET /tasks/{id}/wait
+дожидается ее завершения, а POST /tasks/{id}/cancel отменяет ее
+*/
+type Tasks struct {
+	Manager *MessageManager
+}
+
+func NewTasks(manager *MessageManager) *Tasks {
+	return &Tasks{
+		Manager: manager,
+	}
+}
+
+func (e *Tasks) getExecutorRoute() string {
+	return "/tasks/"
+}
+
+func (e *Tasks) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Путь запроса имеет вид /tasks/{id} или /tasks/{id}/{действие}
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, e.getExecutorRoute()), "/")
+		id, err := strconv.Atoi(parts[0])
+		if err != nil || id <= 0 || len(parts) > 2 {
+			http.Error(w, "[ERROR]: Tasks Invalid task id", http.StatusBadRequest)
+			log.Println("[ERROR]: Tasks Invalid task id: " + r.URL.Path)
+			return
+		}
+		action := ""
+		if len(parts) == 2 {
+			action = parts[1]
+		}
+
+		switch {
+		case action == "" && r.Method == http.MethodGet:
+			e.getTask(w, id)
+		case action == "wait" && r.Method == http.MethodGet:
+			e.waitTask(w, r, id)
+		case action == "cancel" && r.Method == http.MethodPost:
+			e.cancelTask(w, id)
+		case action == "" || action == "wait" || action == "cancel":
+			http.Error(w, "[ERROR]: Tasks Method not allowed", http.StatusMethodNotAllowed)
+		default:
+			http.Error(w, "[ERROR]: Tasks Unknown action", http.StatusNotFound)
+		}
+	}
+}
+
+/*
+getTask отправляет клиенту задачу с определенным номером
+*/
+func (e *Tasks) getTask(w http.ResponseWriter, id int) {
+	task, err := e.Manager.DbConnection.GetTaskFromID(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "[ERROR]: Tasks Task not found", http.StatusNotFound)
+		log.Printf("[ERROR]: Tasks Task %v not found", id)
+		return
+	}
+	if err != nil {
+		http.Error(w, "[ERROR]: Tasks Database error: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: Tasks Database error: " + err.Error())
+		return
+	}
+
+	// Конвертируем отклик в json-отклик
+	jsonResponse, err := json.Marshal(task)
+	if err != nil {
+		http.Error(w, "[ERROR]: Tasks Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: Tasks Can not encoding to JSON: " + err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResponse)
+
+	log.Printf("[OK]: Send task %v was successful", id)
+}
+
+/*
+Время ожидания завершения задачи по умолчанию и наибольшее
+*/
+const (
+	defaultTaskWaitTimeout = 30 * time.Second
+	maxTaskWaitTimeout     = 120 * time.Second
+)
+
+/*
+waitTask держит соединение, пока задача с определенным номером
+не завершится (статус 3, 4 или 5) или не истечет время ожидания
+из параметра timeout, и отправляет клиенту задачу. О завершении
+задачи сообщают исполнители, записавшие ее результат, поэтому
+база данных не опрашивается в цикле
+*/
+func (e *Tasks) waitTask(w http.ResponseWriter, r *http.Request, id int) {
+	timeout := defaultTaskWaitTimeout
+	if text := r.URL.Query().Get("timeout"); text != "" {
+		var err error
+		timeout, err = time.ParseDuration(text)
+		if err != nil || timeout < 0 || timeout > maxTaskWaitTimeout {
+			http.Error(w, "[ERROR]: Tasks Timeout must be from 0s to 120s", http.StatusBadRequest)
+			log.Println("[ERROR]: Tasks Invalid timeout: " + text)
+			return
+		}
+	}
+
+	// Подписываемся до чтения задачи, что бы не пропустить
+	// ее завершение между чтением и ожиданием
+	done, unsubscribe := e.Manager.TaskWaiters.Subscribe(id)
+	defer unsubscribe()
+
+	task, err := e.Manager.DbConnection.GetTaskFromID(id)
+	if err == sql.ErrNoRows {
+		http.Error(w, "[ERROR]: Tasks Task not found", http.StatusNotFound)
+		log.Printf("[ERROR]: Tasks Task %v not found", id)
+		return
+	}
+	if err != nil {
+		http.Error(w, "[ERROR]: Tasks Database error: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: Tasks Database error: " + err.Error())
+		return
+	}
+
+	// Задача еще считается, ждем ее завершения, истечения
+	// времени ожидания или отключения клиента
+	if task.Status != 3 && task.Status != 4 && task.Status != 5 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+		case <-timer.C:
+		case <-r.Context().Done():
+			log.Printf("[INFO]: Client stopped waiting for task %v", id)
+			return
+		}
+	}
+
+	e.getTask(w, id)
+}
+
+/*
+cancelTask отменяет задачу с определенным номером и отправляет
+клиенту отмененную задачу. Уже посчитанную задачу отменить
+нельзя, в этом случае возвращается ошибка 409
+*/
+func (e *Tasks) cancelTask(w http.ResponseWriter, id int) {
+	cancelled, err := e.Manager.CancelTask(id)
+	if err != nil {
+		http.Error(w, "[ERROR]: Tasks Database error: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: Tasks Database error: " + err.Error())
+		return
+	}
+	if !cancelled {
+		// Задачи нет, или она уже завершилась
+		_, err = e.Manager.DbConnection.GetTaskFromID(id)
+		if err == sql.ErrNoRows {
+			http.Error(w, "[ERROR]: Tasks Task not found", http.StatusNotFound)
+			log.Printf("[ERROR]: Tasks Task %v not found", id)
+			return
+		}
+		http.Error(w, "[ERROR]: Tasks Task is already finished", http.StatusConflict)
+		log.Printf("[ERROR]: Tasks Task %v is already finished", id)
+		return
+	}
+
+	log.Printf("[OK]: Task %v was cancelled", id)
+	e.getTask(w, id)
+}
+
+/*
+validationError переводит ошибку разбора выражения в JSON с ошибкой.
+Для синтаксической ошибки заполняются позиция, лексема и причина
+*/
+func validationError(err error) *ValidationErrorJSON {
+	response := &ValidationErrorJSON{
+		Error:  err.Error(),
+		Reason: err.Error(),
+	}
+
+	var syntaxError *expression.SyntaxError
+	if errors.As(err, &syntaxError) {
+		response.Position = syntaxError.Position
+		response.Column = syntaxError.Column()
+		response.Token = syntaxError.Token
+		response.Reason = syntaxError.Reason
+	}
+	return response
+}
+
+/*
+writeValidationError отправляет клиенту ошибку разбора выражения
+в виде JSON с кодом 400
+*/
+func writeValidationError(w http.ResponseWriter, err error) {
+	jsonResponse, err := json.Marshal(validationError(err))
+	if err != nil {
+		http.Error(w, "[ERROR]: Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(jsonResponse)
+}
+
 /*
 GetListExpressionsWithStatuses принимает запрос
 и возвращает список со всеми задачами
@@ -77,14 +813,34 @@ func (e *GetListExpressionsWithStatuses) getExecutorRoute() string {
 
 func (e *GetListExpressionsWithStatuses) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Получем от базы данных список со всеми задачами
-		tasks, err := e.Manager.DbConnection.GetAllTasks()
+		// Разбираем параметры выборки из строки запроса
+		filter, err := parseTaskFilter(r.URL.Query())
 		if err != nil {
-			http.Error(w, "[ERROR]: GetListExpressionsWithStatuses Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
-			log.Println("[ERROR]: GetListExpressionsWithStatuses Can not encoding to JSON: " + err.Error())
+			http.Error(w, "[ERROR]: GetListExpressionsWithStatuses "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: GetListExpressionsWithStatuses " + err.Error())
 			return
 		}
 
+		// Получем от базы данных одну страницу списка задач
+		tasks, cursor, err := e.Manager.DbConnection.GetTasksFromFilter(filter)
+		if err != nil {
+			http.Error(w, "[ERROR]: GetListExpressionsWithStatuses Database error: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: GetListExpressionsWithStatuses Database error: " + err.Error())
+			return
+		}
+
+		// Курсор следующей страницы передаем в заголовке,
+		// что бы тело ответа осталось списком задач
+		if cursor != nil {
+			encoded, err := encodeTaskCursor(cursor)
+			if err != nil {
+				http.Error(w, "[ERROR]: GetListExpressionsWithStatuses Can not encoding cursor: "+err.Error(), http.StatusInternalServerError)
+				log.Println("[ERROR]: GetListExpressionsWithStatuses Can not encoding cursor: " + err.Error())
+				return
+			}
+			w.Header().Set("X-Next-Cursor", encoded)
+		}
+
 		// Конвертируем отклик в json-отклик
 		jsonResponse, err := json.Marshal(tasks)
 		if err != nil {
@@ -94,14 +850,108 @@ func (e *GetListExpressionsWithStatuses) getExecutorHandler() func(http.Response
 		}
 
 		// Заполняем тело запроса и заголовки
-		w.WriteHeader(http.StatusOK)
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
 		w.Write(jsonResponse)
 
 		log.Println("[OK]: Send task list was successful")
 	}
 }
 
+/*
+Количество задач на странице списка задач по умолчанию и наибольшее
+*/
+const (
+	defaultTaskListLimit = 100
+	maxTaskListLimit     = 1000
+)
+
+/*
+parseTaskFilter разбирает параметры выборки списка задач:
+status (один или несколько статусов через запятую), from и to
+(промежуток времени создания задачи в формате RFC3339), expression
+(подстрока выражения), sort (поле сортировки, с минусом в начале
+по убыванию), limit (количество задач) и cursor (курсор страницы
+из заголовка X-Next-Cursor предыдущего ответа)
+*/
+func parseTaskFilter(query url.Values) (TaskFilter, error) {
+	filter := TaskFilter{
+		Expression: query.Get("expression"),
+		Sort:       "id",
+		Limit:      defaultTaskListLimit,
+	}
+
+	if statuses := query.Get("status"); statuses != "" {
+		for _, text := range strings.Split(statuses, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(text))
+			if err != nil {
+				return filter, errors.New("Invalid status: " + text)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	var err error
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return filter, errors.New("Invalid from time: " + from)
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return filter, errors.New("Invalid to time: " + to)
+		}
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		filter.Descending = strings.HasPrefix(sort, "-")
+		filter.Sort = strings.TrimPrefix(sort, "-")
+		if !IsTaskSortField(filter.Sort) {
+			return filter, errors.New("Invalid sort field: " + filter.Sort)
+		}
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil || filter.Limit <= 0 || filter.Limit > maxTaskListLimit {
+			return filter, errors.New("Limit must be from 1 to 1000")
+		}
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		if filter.Cursor, err = decodeTaskCursor(cursor); err != nil {
+			return filter, errors.New("Invalid cursor")
+		}
+	}
+	return filter, nil
+}
+
+/*
+encodeTaskCursor кодирует курсор страницы в строку для клиента
+*/
+func encodeTaskCursor(cursor *TaskCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+/*
+decodeTaskCursor восстанавливает курсор страницы из строки клиента
+*/
+func decodeTaskCursor(text string) (*TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(text)
+	if err != nil {
+		return nil, err
+	}
+	var cursor TaskCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
 /*
 SetExecutionTimeOfOperations принимает запрос со списком
 времени выполнения для каждой операции
@@ -132,191 +982,626 @@ func (e *SetTimeOfOperations) getExecutorHandler() func(http.ResponseWriter, *ht
 			return
 		}
 
-		// Записываем время операций в словарь менеджера
-		for key, val := range message.Times {
-			e.Manager.OperationTimeMap[key] = val
-		}
+		// Проверяем, что время задано только известным
+		// операциям и функциям и не бывает отрицательным
+		for key, val := range message.Times {
+			if !expression.IsOperation(key) || val < 0 {
+				http.Error(w, "[ERROR]: SetTimeOfOperations Unknown operation or negative time: "+key, http.StatusBadRequest)
+				log.Println("[ERROR]: SetTimeOfOperations Unknown operation or negative time: " + key)
+				return
+			}
+		}
+
+		// Записываем время операций в словарь менеджера
+		e.Manager.Mutex.Lock()
+		for key, val := range message.Times {
+			e.Manager.OperationTimeMap[key] = val
+		}
+		e.Manager.Mutex.Unlock()
+
+		// Записываем время операций в базу данных
+		err = e.Manager.DbConnection.SetTimesToOperation(message)
+		if err != nil {
+			http.Error(w, "[ERROR]: Database error: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: Database error: " + err.Error())
+			return
+		}
+
+		log.Println("[OK]: Set operation time was successful")
+	}
+}
+
+/*
+GetTimeOfOperations возвращает время выполнения
+всех операций и встроенных функций выражения
+*/
+type GetTimeOfOperations struct {
+	Manager *MessageManager
+}
+
+func NewGetTimeOfOperations(manager *MessageManager) *GetTimeOfOperations {
+	return &GetTimeOfOperations{
+		Manager: manager,
+	}
+}
+
+func (e *GetTimeOfOperations) getExecutorRoute() string {
+	return "/getExecutionTimeOfOperations"
+}
+
+func (e *GetTimeOfOperations) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Копируем словарь менеджера, что бы не держать мутекс во время ответа
+		response := TimeOfOperationJSON{
+			Times: make(map[string]int),
+		}
+		e.Manager.Mutex.Lock()
+		for key, val := range e.Manager.OperationTimeMap {
+			response.Times[key] = val
+		}
+		e.Manager.Mutex.Unlock()
+
+		// Конвертируем отклик в json-отклик
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			http.Error(w, "[ERROR]: GetTimeOfOperations Can not encoding to JSON"+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: GetTimeOfOperations Can not encoding to JSON" + err.Error())
+			return
+		}
+
+		// Заполняем тело запроса и заголовки
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+
+		log.Println("[OK]: Send operation time was successful")
+	}
+}
+
+/*
+Время, которое запрос вычислителя на получение задачи ждет
+новую задачу, по умолчанию и наибольшее
+*/
+const (
+	defaultDispatchTimeout = 30 * time.Second
+	maxDispatchTimeout     = 60 * time.Second
+)
+
+/*
+GetReadyTaskToSolving принимает запрос с информацией
+о вычислителе и возвращает задачу готовую к выполнению
+вместе с информацией о времени выполнения арифметических операций.
+Если задачи нет, то запрос ждет ее до истечения времени из параметра
+timeout (по умолчанию 30 секунд), а затем возвращает код 204
+*/
+type GetReadyTaskToSolving struct {
+	Manager *MessageManager
+}
+
+func NewGetReadyTaskToSolving(manager *MessageManager) *GetReadyTaskToSolving {
+	return &GetReadyTaskToSolving{
+		Manager: manager,
+	}
+}
+
+func (e *GetReadyTaskToSolving) getExecutorRoute() string {
+	return "/getTaskToSolving"
+}
+
+func (e *GetReadyTaskToSolving) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Декодируем тело запроса в JSON нужной нам структуры
+		var message SolverRequestJSON
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&message)
+		if err != nil {
+			http.Error(w, "[ERROR]: GetReadyTaskToSolving Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: GetReadyTaskToSolving Decoding JSON was failed: " + err.Error())
+			return
+		}
+
+		// Регистрируем вычислитель, если его еще нет в системе
+		e.Manager.registerSolver(message.SolverName)
+
+		// Держим запрос, пока для вычислителя не появится задача
+		// или не истечет время ожидания
+		timeout := defaultDispatchTimeout
+		if text := r.URL.Query().Get("timeout"); text != "" {
+			timeout, err = time.ParseDuration(text)
+			if err != nil || timeout < 0 || timeout > maxDispatchTimeout {
+				http.Error(w, "[ERROR]: GetReadyTaskToSolving Timeout must be from 0s to 60s", http.StatusBadRequest)
+				log.Println("[ERROR]: GetReadyTaskToSolving Invalid timeout: " + text)
+				return
+			}
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		for {
+			// Сигнал берем до поиска задачи, что бы не пропустить
+			// задачу, добавленную между поиском и ожиданием
+			available := e.Manager.TaskAvailable.Wait()
+
+			// Вычислителю в режиме операций отдаем одну операцию графа задачи
+			var sent bool
+			if message.Mode == OperationMode {
+				sent = e.sendOperation(w, message.SolverName)
+			} else {
+				sent = e.sendExpression(w, message.SolverName)
+			}
+			if sent {
+				return
+			}
+
+			// Задачи нет, ждем сигнала о новой задаче
+			select {
+			case <-available:
+				// Вычислитель мог отключиться, пока ждал,
+				// тогда задачу ему не выдаем
+				if r.Context().Err() != nil {
+					return
+				}
+			case <-timer.C:
+				// Задача так и не появилась, вычислитель просто спросит снова
+				w.WriteHeader(http.StatusNoContent)
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+/*
+sendExpression отдает вычислителю выражение задачи целиком
+
+Returns:
+
+	bool: false, если задач нет и ответ вычислителю не отправлен
+*/
+func (e *GetReadyTaskToSolving) sendExpression(w http.ResponseWriter, solverName string) bool {
+	task, err := e.Manager.DispatchExpression(solverName)
+	if err != nil {
+		http.Error(w, "[ERROR]: GetReadyTaskToSolving Database error: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: GetReadyTaskToSolving Database error: " + err.Error())
+		return true
+	}
+	if task == nil {
+		return false
+	}
+
+	e.writeTask(w, solverName, task)
+	log.Println("[OK]: Send expression to solver")
+	return true
+}
+
+/*
+sendOperation отдает вычислителю одну готовую операцию графа задачи
+
+Returns:
+
+	bool: false, если операций и задач нет и ответ вычислителю не отправлен
+*/
+func (e *GetReadyTaskToSolving) sendOperation(w http.ResponseWriter, solverName string) bool {
+	task, err := e.Manager.DispatchOperation(solverName)
+	if err != nil {
+		http.Error(w, "[ERROR]: GetReadyTaskToSolving Database error: "+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: GetReadyTaskToSolving Database error: " + err.Error())
+		return true
+	}
+	if task == nil {
+		return false
+	}
+
+	e.writeTask(w, solverName, task)
+	log.Println("[OK]: Send operation to solver")
+	return true
+}
+
+/*
+writeTask отправляет вычислителю выданную ему задачу или операцию.
+Если ответ не удалось сформировать, то задача возвращается в обработку
+*/
+func (e *GetReadyTaskToSolving) writeTask(w http.ResponseWriter, solverName string, task *TaskToSendToSolver) {
+	jsonResponse, err := json.Marshal(task)
+	if err != nil {
+		http.Error(w, "[ERROR]: GetReadyTaskToSolving Can not encoding to JSON"+err.Error(), http.StatusInternalServerError)
+		log.Println("[ERROR]: GetReadyTaskToSolving Can not encoding to JSON" + err.Error())
+		// Если что то пошло не так, то отнимаем задачу у вычислителя
+		e.Manager.Mutex.Lock()
+		id := e.Manager.releaseSolver(e.Manager.SolverInfoMap[solverName])
+		e.Manager.freeSolver(solverName)
+		e.Manager.Mutex.Unlock()
+		e.Manager.requeueTask(solverName, id)
+		return
+	}
+
+	// Заполняем тело запроса и заголовки
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResponse)
+}
+
+/*
+DispatchExpression выдает вычислителю выражение задачи целиком,
+беря из базы данных первую задачу со статусом 1 (принята)
+и переводя ее в статус 2 (отдана вычислителю)
+
+Parameters:
+
+	string: Имя вычислителя
+
+Returns:
+
+	*TaskToSendToSolver: Задача для вычислителя или nil, если задач нет
+	error: Ошибка базы данных
+*/
+func (manager *MessageManager) DispatchExpression(solverName string) (*TaskToSendToSolver, error) {
+	// Ждем пока будет доступ к базе данных
+	// как только доступ появился, блокируем остальным потокам
+	// возможность выдавать задачи вычислителям, что бы избежать
+	// вероятность выдачи одной задачи двум вычислителям
+	manager.DbLockChan <- 0
+
+	// Запрашиваем у базы данных список задач,
+	// принятых, но не отданных вычистилелям (статус 1)
+	// если не удалось успешно отправить запрос, прерываем выдачу задачи,
+	// разблокируем доступ параллельным запросам на выдачу задач
+	tasks, err := manager.DbConnection.GetTasksFromStatus(1)
+	if err != nil {
+		<-manager.DbLockChan
+		return nil, err
+	}
+
+	// Если список пуст, значит задач нет, вычислитель
+	// будет ждать новую задачу, разблокируем возможность получать задачи
+	if len(tasks) == 0 {
+		<-manager.DbLockChan
+		return nil, nil
+	}
+
+	// Берем первую задачу в списке, пробуем изменить ее статус
+	// с 1 (принята в обработку) на 2 (отдана вычислителю). Если задачу
+	// успели отменить, то берем следующую. При ошибке доступа к таблице
+	// (база данных упала) прерываем выдачу задачи,
+	// разблокируем доступ параллельным запросам на выдачу задач
+	var claimed *TaskJSON
+	for i := range tasks {
+		ok, err := manager.DbConnection.DispatchTaskFromID(
+			time.Now().Add(manager.PredictExecutionTime(tasks[i].Expression)), tasks[i].ID)
+		if err != nil {
+			<-manager.DbLockChan
+			return nil, err
+		}
+		if ok {
+			claimed = &tasks[i]
+			break
+		}
+	}
+
+	// После того как из таблицы была успешно взята задача,
+	// и ее успешно удалось перевести в состояние 2,
+	// разблокируем доступ параллельным запросам на выдачу задач
+	<-manager.DbLockChan
+	if claimed == nil {
+		return nil, nil
+	}
+
+	// Записываем в словарь о том какой вычислитель какую задачу выполняет
+	manager.Mutex.Lock()
+	task := &TaskToSendToSolver{
+		Expression:  claimed.Expression,
+		Variables:   claimed.Variables,
+		Times:       manager.operationTimes(),
+		NumericMode: claimed.NumericMode,
+		Precision:   claimed.Precision,
+		TaskID:      claimed.ID,
+	}
+	solver := manager.SolverInfoMap[solverName]
+	solver.InfoString = "Working"
+	solver.SolvingNowExpression = claimed.Expression
+	solver.taskID = claimed.ID
+	manager.Mutex.Unlock()
+
+	claimed.Status = 2
+	manager.EventBus.PublishTask(EventTaskDispatched, *claimed)
+	return task, nil
+}
+
+/*
+DispatchOperation выдает вычислителю одну готовую операцию из графов
+задач, которые уже считаются. Если готовых операций нет, то берет
+из базы данных новую задачу (статус 1), разбивает ее на операции
+и отдает первую готовую из них. Так одно длинное выражение
+считают сразу все вычислители, работающие в режиме операций
+
+Parameters:
+
+	string: Имя вычислителя
+
+Returns:
+
+	*TaskToSendToSolver: Операция для вычислителя или nil, если операций и задач нет
+	error: Ошибка базы данных
+*/
+func (manager *MessageManager) DispatchOperation(solverName string) (*TaskToSendToSolver, error) {
+	// Задачи, которые не нужно считать по операциям, завершаются
+	// уже после разблокировки выдачи задач: отложенные вызовы
+	// выполняются в обратном порядке
+	finished := make([]TaskJSON, 0)
+	defer func() {
+		for _, task := range finished {
+			err := manager.FinishTask(task.Status, task.ID, task.Result, task.ResultDecimal)
+			if err != nil {
+				log.Println("[ERROR]: Database error: " + err.Error())
+			}
+		}
+	}()
+
+	// Блокируем выдачу задач остальным потокам,
+	// что бы одна операция не досталась двум вычислителям
+	manager.DbLockChan <- 0
+	defer func() { <-manager.DbLockChan }()
+
+	// Словарь со временем операций меняется под мутексом,
+	// поэтому граф новой задачи строим по его копии
+	manager.Mutex.Lock()
+	graph, job := manager.findReadyOperation()
+	times := manager.operationTimes()
+	manager.Mutex.Unlock()
+
+	// Готовых операций нет, пробуем разбить на операции новую задачу
+	if job == nil {
+		tasks, err := manager.DbConnection.GetTasksFromStatus(1)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, task := range tasks {
+			// Сначала забираем задачу, если ее успели отменить,
+			// то граф для нее не строим и берем следующую
+			ok, err := manager.DbConnection.DispatchTaskFromID(
+				time.Now().Add(manager.PredictExecutionTime(task.Expression)), task.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			task.Status = 2
+			manager.EventBus.PublishTask(EventTaskDispatched, task)
+
+			graph, err = NewTaskGraph(task, times)
+			if err != nil {
+				// Выражение не удалось разобрать, задача завершается с ошибкой
+				log.Println("[ERROR]: GetReadyTaskToSolving Can not parse expression: " + err.Error())
+				finished = append(finished, TaskJSON{ID: task.ID, Status: 4, Result: err.Error()})
+				continue
+			}
+
+			// Выражение без операций, то есть просто число, считать не нужно
+			if graph.IsDone() {
+				finished = append(finished, TaskJSON{ID: task.ID, Status: 3,
+					Result: graph.Value.String(), ResultDecimal: graph.Decimal(graph.Value)})
+				continue
+			}
+
+			manager.Mutex.Lock()
+			manager.TaskGraphMap[graph.TaskID] = graph
+			manager.Mutex.Unlock()
+			job = graph.NextReady()
+			break
+		}
+	}
+
+	// Если и новых задач нет, значит вычислитель будет ждать новую задачу
+	if job == nil {
+		return nil, nil
+	}
+
+	// Записываем в словарь о том какой вычислитель какую операцию выполняет
+	manager.Mutex.Lock()
+	defer manager.Mutex.Unlock()
+	args := make([]string, 0, len(job.Args))
+	for _, arg := range job.Args {
+		args = append(args, arg.String())
+	}
+	task := &TaskToSendToSolver{
+		Expression:  graph.Expression,
+		Times:       manager.operationTimes(),
+		NumericMode: graph.NumericMode,
+		Precision:   graph.Precision,
+		TaskID:      graph.TaskID,
+		OperationID: job.ID,
+		Args:        args,
+		Operation:   job.Operation,
+		Time:        manager.OperationTimeMap[job.Operation],
+	}
+
+	graph.Dispatch(job, solverName)
+	solver := manager.SolverInfoMap[solverName]
+	solver.InfoString = "Working"
+	solver.SolvingNowExpression = job.String()
+	solver.graph = graph
+	solver.operation = job
+	return task, nil
+}
+
+/*
+operationTimes возвращает копию словаря со временем выполнения
+операций, которую можно отправить вычислителю без мутекса.
+Вызывается под мутексом менеджера
+*/
+func (manager *MessageManager) operationTimes() map[string]int {
+	times := make(map[string]int, len(manager.OperationTimeMap))
+	for operation, seconds := range manager.OperationTimeMap {
+		times[operation] = seconds
+	}
+	return times
+}
+
+/*
+findReadyOperation ищет готовую к выдаче операцию в графах задач,
+начиная с самых старых задач. Вызывается под мутексом менеджера
+*/
+func (manager *MessageManager) findReadyOperation() (*TaskGraph, *OperationJob) {
+	ids := make([]int, 0, len(manager.TaskGraphMap))
+	for id := range manager.TaskGraphMap {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
 
-		// Записываем время операций в базу данных
-		err = e.Manager.DbConnection.SetTimesToOperation(message)
-		if err != nil {
-			http.Error(w, "[ERROR]: Database error: "+err.Error(), http.StatusBadRequest)
-			log.Println("[ERROR]: Database error: " + err.Error())
-			return
+	for _, id := range ids {
+		graph := manager.TaskGraphMap[id]
+		if job := graph.NextReady(); job != nil {
+			return graph, job
 		}
+	}
+	return nil, nil
+}
 
-		log.Println("[OK]: Set operation time was successful")
+/*
+PredictExecutionTime предсказывает время вычисления выражения
+как самый долгий путь по графу зависимостей его операций
+при текущих настройках времени выполнения операций.
+Если выражение не разбирается, то возвращает ноль.
+Настройки копируются под мутексом менеджера, поэтому
+вызывать функцию под мутексом нельзя
+*/
+func (manager *MessageManager) PredictExecutionTime(expr string) time.Duration {
+	manager.Mutex.Lock()
+	times := manager.operationTimes()
+	manager.Mutex.Unlock()
+
+	duration, err := expression.Estimate(expr, times)
+	if err != nil {
+		return 0
 	}
+	return duration
 }
 
 /*
-GetReadyTaskToSolving принимает запрос с информацией
-о вычислителе и возвращает задачу готовую к выполнению
-вместе с информацией о времени выполнения арифметических операций
+ExplainExpression принимает запрос с выражением и, не запуская
+вычисление, возвращает план его выполнения: дерево выражения,
+этапы, которые вычислитель выполнит параллельно, время выполнения
+каждой операции и предсказанное общее время вычисления
 */
-type GetReadyTaskToSolving struct {
+type ExplainExpression struct {
 	Manager *MessageManager
 }
 
-func NewGetReadyTaskToSolving(manager *MessageManager) *GetReadyTaskToSolving {
-	return &GetReadyTaskToSolving{
+func NewExplainExpression(manager *MessageManager) *ExplainExpression {
+	return &ExplainExpression{
 		Manager: manager,
 	}
 }
 
-func (e *GetReadyTaskToSolving) getExecutorRoute() string {
-	return "/getTaskToSolving"
+func (e *ExplainExpression) getExecutorRoute() string {
+	return "/explainExpression"
 }
 
-func (e *GetReadyTaskToSolving) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+func (e *ExplainExpression) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Декодируем тело запроса в JSON нужной нам структуры
-		var message SolverRequestJSON
+		var message ExpressionRequestJSON
 		decoder := json.NewDecoder(r.Body)
 		err := decoder.Decode(&message)
 		if err != nil {
-			http.Error(w, "[ERROR]: GetReadyTaskToSolving Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
-			log.Println("[ERROR]: GetReadyTaskToSolving Decoding JSON was failed: " + err.Error())
+			http.Error(w, "[ERROR]: ExplainExpression Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: ExplainExpression Decoding JSON was failed: " + err.Error())
 			return
 		}
 
-		// Проверяем зарегистрирован ли вычислитель в системе
-		if _, ok := e.Manager.SolverInfoMap[message.SolverName]; !ok {
-			// Если нет, то регистрируем
-			e.Manager.Mutex.Lock()
-			e.Manager.SolverInfoMap[message.SolverName] = &Solver{
-				SolverName:           message.SolverName,
-				SolvingNowExpression: "None",
-				LastPing:             time.Now(),
-				InfoString:           "Registered",
-			}
-			e.Manager.Mutex.Unlock()
-		}
-
-		// Ждем пока будет доступ к базе данных
-		// как только доступ появился, блокируем остальным потокам
-		// возможность выдавать задачи вычислителям, что бы избежать
-		// вероятность выдачи одной задачи двум вычислителям
-		e.Manager.DbLockChan <- 0
-
-		// Запрашиваем у базы данных список задач,
-		// принятых, но не отданных вычистилелям (статус 1)
-		// если не удалось успешно отправить запрос, прерываем выдачу задачи,
-		// разблокируем доступ параллельным запросам на выдачу задач
-		tasks, err := e.Manager.DbConnection.GetTasksFromStatus(1)
+		// Разбираем выражение, если не получилось, то возвращаем причину ошибки
+		err = checkExpressionLength(message.Expression)
 		if err != nil {
-			http.Error(w, "[ERROR]:GetReadyTaskToSolving Database error: "+err.Error(), http.StatusInternalServerError)
-			log.Println("[ERROR]: GetReadyTaskToSolving Database error: " + err.Error())
-			<- e.Manager.DbLockChan
+			writeValidationError(w, err)
+			log.Println("[ERROR]: ExplainExpression " + err.Error())
 			return
 		}
-
-		// Если список пуст, значит задач нет,
-		// значит отказываем вычислителю в выдаче задачи,
-		// разблокируем возможность получать задачи
-		if len(tasks) == 0 {
-			http.Error(w, "[INFO]: GetReadyTaskToSolving Receipt of task denied", http.StatusInternalServerError)
-			log.Println("[INFO]: GetReadyTaskToSolving Receipt of task denied")
-			<- e.Manager.DbLockChan
+		tree, err := expression.Parse(message.Expression)
+		if err != nil {
+			writeValidationError(w, err)
+			log.Println("[ERROR]: ExplainExpression Can not parse expression: " + err.Error())
 			return
 		}
 
-		// Перем первую задачу в списке(почемы бы и нет), пробуем
-		// изменить ее статус с 1 (принята в обработку) на 2 (отдана вычислителю)
-		// при ошибке доступа к таблице (база данных упала) прерываем выдачу задачи,
-		// разблокируем доступ параллельным запросам на выдачу задач
-		err = e.Manager.DbConnection.UpdateStatusAndTimeFromExpression(
-			time.Now().Add(e.findExecutionTime(tasks[0].Expression)), 
-			2, 
-			tasks[0].Expression)
-		if err != nil {
-			http.Error(w, "[ERROR]: Database error: "+err.Error(), http.StatusInternalServerError)
-			log.Println("[ERROR]: Database error: " + err.Error())
-			<- e.Manager.DbLockChan
-			return
+		// Строим план с текущими настройками времени выполнения операций
+		e.Manager.Mutex.Lock()
+		times := make(map[string]int)
+		for key, val := range e.Manager.OperationTimeMap {
+			times[key] = val
 		}
-		//EndTime:    message.TimeToSend.Add(e.findExecutionTime(message.Expression)),
+		e.Manager.Mutex.Unlock()
 
-		// После того как из таблицы была успешно взята задача,
-		// и ее успешно удалось перевести в состояние 2,
-		// разблокируем доступ параллельным запросам на выдачу задач
-		<- e.Manager.DbLockChan
+		tree = expression.Balance(tree, times)
+		response := ExplainResponseJSON{
+			Expression: message.Expression,
+			Variables:  expression.Variables(tree),
+			Tree:       explainNode(tree),
+			Stages:     make([]ExplainStageJSON, 0),
+			Times:      times,
+			TotalTime:  int(expression.CriticalPath(tree, times) / time.Second),
+		}
 
-		// Отдаем вычислителю задачу. Формируем JSON
-		tastToSend := &TaskToSendToSolver{
-			Expression: tasks[0].Expression,
-			Times:      e.Manager.OperationTimeMap,
+		for _, stage := range expression.PlanSchedule(tree, times) {
+			stageJSON := ExplainStageJSON{
+				Start:      int(stage.Start / time.Second),
+				Operations: make([]ExplainOperationJSON, 0),
+			}
+			for _, operation := range stage.Operations {
+				stageJSON.Operations = append(stageJSON.Operations, ExplainOperationJSON{
+					Expression: operation.Node.String(),
+					Operation:  operation.Node.Operation,
+					Duration:   int(operation.Duration / time.Second),
+					Start:      int(operation.Start / time.Second),
+					End:        int(operation.End / time.Second),
+				})
+			}
+			response.Stages = append(response.Stages, stageJSON)
 		}
 
 		// Конвертируем отклик в json-отклик
-		jsonResponse, err := json.Marshal(tastToSend)
+		jsonResponse, err := json.Marshal(response)
 		if err != nil {
-			http.Error(w, "[ERROR]: GetReadyTaskToSolving Can not encoding to JSON"+err.Error(), http.StatusInternalServerError)
-			log.Println("[ERROR]: GetReadyTaskToSolving Can not encoding to JSON" + err.Error())
-			// Если что то пошло не так, то отнимаем ее у вычислителя
-			e.Manager.DbConnection.UpdateStatusFromExpression(1, tasks[0].Expression)
+			http.Error(w, "[ERROR]: ExplainExpression Can not encoding to JSON"+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: ExplainExpression Can not encoding to JSON" + err.Error())
 			return
 		}
 
-		// Записываем в словарь о том какой вычислитель какую задачу выполняет
-		e.Manager.Mutex.Lock()
-		solver := e.Manager.SolverInfoMap[message.SolverName]
-		solver.InfoString = "Working"
-		solver.SolvingNowExpression = tasks[0].Expression
-		e.Manager.SolverInfoMap[message.SolverName] = solver
-		e.Manager.Mutex.Unlock()
-
 		// Заполняем тело запроса и заголовки
-		w.WriteHeader(http.StatusOK)
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
 		w.Write(jsonResponse)
 
-		log.Println("[OK]: Send expression to solver")
+		log.Println("[OK]: Send expression plan was successful")
 	}
 }
 
 /*
-findExecutionTime находит время выполнения задачи по выражению
+explainNode переводит дерево выражения в JSON структуру
 */
-func (e *GetReadyTaskToSolving) findExecutionTime(expression string) time.Duration {
-	// Создаем массив операций
-	arrayOfOperation := make([]string, 0)
-	for _, ch := range strings.Split(expression, "") {
-		if ch == "+" || ch == "-" || ch == "/" || ch == "*" {
-			arrayOfOperation = append(arrayOfOperation, ch)
-		}
+func explainNode(node *expression.Node) *ExplainNodeJSON {
+	if node.IsVariable() {
+		return &ExplainNodeJSON{Variable: node.Variable}
 	}
-
-	x := make([]int, 0)
-	t1 := 0
-	t2 := 0
-	for i, val := range arrayOfOperation {
-		if val == "*" || val == "/" {
-			t1 += e.Manager.OperationTimeMap[val]
-			if i == len(arrayOfOperation)-1 {
-				x = append(x, t1)
-				t1 = 0
-			}
-
-			if i < len(arrayOfOperation)-1 && 
-				(arrayOfOperation[i+1] == "+" || arrayOfOperation[i+1] == "-"){
-				x = append(x, t1)
-				t1 = 0
-			}
-			log.Printf("Find * or /, t1 = %v", t1)
-		}
-
-		if val == "+" || val == "-" {
-			t2 += e.Manager.OperationTimeMap[val]
-			log.Printf("Find + or -, t2 = %v", t2)
+	if node.IsLeaf() {
+		value := node.Value
+		return &ExplainNodeJSON{Value: &value}
+	}
+	if node.IsFunction() {
+		arguments := make([]*ExplainNodeJSON, 0, len(node.Arguments))
+		for _, argument := range node.Arguments {
+			arguments = append(arguments, explainNode(argument))
 		}
+		return &ExplainNodeJSON{Operation: node.Operation, Arguments: arguments}
 	}
 
-	sort.Slice(x, func(i, j int) bool { return i > j })
-	if len(x) != 0 {
-		t2 += x[0]
+	return &ExplainNodeJSON{
+		Operation: node.Operation,
+		Left:      explainNode(node.Left),
+		Right:     explainNode(node.Right),
 	}
-	return time.Duration(t2) * time.Second
 }
 
 /*
@@ -349,56 +1634,338 @@ func (e *SetResultOfSolving) getExecutorHandler() func(http.ResponseWriter, *htt
 			return
 		}
 
-		// Проверяем на всякий случай есть ли посчитаное выражение в
-		// базе данных, если нет, значит ответ не записываем
-		tasks, err := e.Manager.DbConnection.GetTasksFromExpession(message.Expression)
-		if err != nil || len(tasks) == 0 {
+		// Записываем результат, при ошибке базы данных
+		// вычислитель сможет отправить ответ повторно
+		err = e.Manager.SaveResult(message)
+		if err != nil {
 			http.Error(w, "[ERROR]: Database error: "+err.Error(), http.StatusInternalServerError)
 			log.Println("[ERROR]: Database error: " + err.Error())
 			return
 		}
 
-		// Проверяем ответ на корректность. Если ответ
-		// это пустая строка или статус код не 0 (ошибка на стороне вычислителя),
-		// значит вычислитель оподливился, меняем статут задачи с 2 (отдана
-		// вычислителю) на 4 (ошибка выполнения, передана обратно в обработку).
-		// Отправляем вычислителю код 200, что бы он не пытался снова отправить ответ
-		if message.Result == "" || message.Status != 0 {
-			w.WriteHeader(http.StatusOK)
-			log.Println("[ERROR]: Result in invalid")
-			e.Manager.DbConnection.UpdateStatusFromExpression(4, message.Expression)
-			// Записываем в словарь о том что вычислитель свободен
-			e.Manager.Mutex.Lock()
-			solver := e.Manager.SolverInfoMap[message.SolverName]
-			solver.InfoString = "Free"
-			solver.SolvingNowExpression = "None"
-			e.Manager.SolverInfoMap[message.SolverName] = solver
-			e.Manager.Mutex.Unlock()
-			return
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+/*
+SaveResult записывает результат, который прислал вычислитель,
+и освобождает вычислитель для новой задачи
+
+Parameters:
+
+	ResultFromSolver: Результат задачи или одной операции графа задачи
+
+Returns:
+
+	error: Ошибка базы данных, в этом случае ответ нужно отправить повторно
+*/
+func (manager *MessageManager) SaveResult(message ResultFromSolver) error {
+	// Результат одной операции графа задачи обрабатываем отдельно
+	if message.OperationID != 0 {
+		return manager.saveOperationResult(message)
+	}
+
+	// Проверяем на всякий случай есть ли посчитаная задача в
+	// базе данных, если нет, значит ответ не записываем
+	task, err := manager.DbConnection.GetTaskFromID(message.TaskID)
+	if err == sql.ErrNoRows {
+		log.Printf("[INFO]: Task %v is not found, result is ignored", message.TaskID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Ответ принимаем только от вычислителя, которому отдана задача
+	manager.Mutex.Lock()
+	solver, ok := manager.SolverInfoMap[message.SolverName]
+	owner := ok && solver.taskID == task.ID
+	manager.Mutex.Unlock()
+
+	// Ответ на отмененную задачу (статус 5) не записываем,
+	// вычислитель просто освобождается для новой задачи
+	if task.Status == 5 && owner {
+		manager.Mutex.Lock()
+		manager.freeSolver(message.SolverName)
+		manager.Mutex.Unlock()
+		log.Printf("[INFO]: Task %v is cancelled, result is ignored", task.ID)
+		return nil
+	}
+
+	// Задача уже посчитана, отменена или отдана другому вычислителю,
+	// такой ответ не записываем и чужую задачу вычислителя не трогаем
+	if task.Status != 2 || !owner {
+		log.Printf("[INFO]: Task %v is not dispatched to solver %v, result is ignored",
+			task.ID, message.SolverName)
+		return nil
+	}
+
+	// Проверяем ответ на корректность. Если ответ
+	// это пустая строка или статус код не 0 (ошибка на стороне вычислителя),
+	// значит вычислитель оподливился, меняем статут задачи с 2 (отдана
+	// вычислителю) на 4 (ошибка выполнения, передана обратно в обработку).
+	// Вычислителю отвечаем без ошибки, что бы он не пытался снова отправить ответ
+	if message.Result == "" || message.Status != 0 {
+		log.Println("[ERROR]: Result in invalid")
+		manager.FinishTask(4, message.TaskID, message.Result, "")
+		// Записываем в словарь о том что вычислитель свободен
+		manager.Mutex.Lock()
+		manager.freeSolver(message.SolverName)
+		manager.Mutex.Unlock()
+		return nil
+	}
+
+	// Если все верно, то пробуем изменить статус задачи в
+	// базе данных с 2 (отдана вычислителю) на 3 (успешно посчитано)
+	// и записывать в базу данных результат
+	err = manager.FinishTask(3, message.TaskID, message.Result, message.Decimal)
+	if err != nil {
+		return err
+	}
+
+	// Записываем в словарь о том что вычислитель свободен
+	manager.Mutex.Lock()
+	manager.freeSolver(message.SolverName)
+	manager.Mutex.Unlock()
+
+	log.Println("[OK]: Get result from solver successful")
+	return nil
+}
+
+/*
+saveOperationResult записывает результат одной операции в граф задачи.
+Когда посчитан корень графа, результат задачи записывается в базу данных.
+Если вычислитель вернул ошибку (например, деление на ноль),
+то вся задача завершается с ошибкой. Граф меняется под мутексом
+менеджера, а запись в базу данных и рассылка о завершении задачи
+идут уже без него, что бы не задерживать остальные запросы
+*/
+func (manager *MessageManager) saveOperationResult(message ResultFromSolver) error {
+	manager.Mutex.Lock()
+
+	// Проверяем что задача еще считается и операция была выдана,
+	// иначе ответ уже не нужен (например, задача завершилась с ошибкой)
+	graph, ok := manager.TaskGraphMap[message.TaskID]
+	var job *OperationJob
+	if ok {
+		job = graph.Operation(message.OperationID)
+	}
+	solver, ok := manager.SolverInfoMap[message.SolverName]
+	// Операция вычислителя, от которого пришел ответ
+	owned := ok && solver.operation != nil && solver.graph.TaskID == message.TaskID &&
+		solver.operation.ID == message.OperationID
+	if job == nil || job.Status != OperationDispatched || job.SolverName != message.SolverName || !owned {
+		// Вычислитель освобождаем, только если он все еще
+		// считает эту операцию, а не уже выданную другую
+		if owned {
+			manager.freeSolver(message.SolverName)
 		}
+		manager.Mutex.Unlock()
+		log.Println("[INFO]: Operation is not expected, result is ignored")
+		return nil
+	}
 
-		// Если все верно, то пробуем изменить статус задачи в
-		// базе данных с 2 (отдана вычислителю) на 3 (успешно посчитано)
-		// и записывать в базу данных результат
-		err = e.Manager.DbConnection.UpdateStatusAndResultFromExpression(
-			3, message.Expression, message.Result)
-		if err != nil {
-			http.Error(w, "[ERROR]: Database error: "+err.Error(), http.StatusInternalServerError)
-			log.Println("[ERROR]: Database error: " + err.Error())
-			return
+	// Результат промежуточной операции записываем в граф, он может
+	// сделать готовыми следующие операции графа
+	value, err := expression.ParseNumber(message.Result, graph.NumericMode)
+	failed := message.Status != 0 || err != nil
+	if !failed && job != graph.Root {
+		graph.SetResult(job, value)
+		manager.freeSolver(message.SolverName)
+		manager.TaskAvailable.Broadcast()
+		manager.Mutex.Unlock()
+		log.Println("[OK]: Get operation result from solver successful")
+		return nil
+	}
+
+	// Иначе задача завершается. Пока результат пишется в базу,
+	// операция отмечена, что бы повторный ответ был пропущен
+	job.Status = OperationFinishing
+	manager.Mutex.Unlock()
+
+	// Если ответ пустой, вычислитель вернул ошибку или ответ не является
+	// числом, то задача завершается с ошибкой, иначе записываем результат
+	// корня графа. При ошибке базы вычислитель отправит ответ повторно
+	if failed {
+		log.Println("[ERROR]: Result in invalid")
+		err = manager.FinishTask(4, graph.TaskID, message.Result, "")
+	} else {
+		err = manager.FinishTask(3, graph.TaskID, value.String(), graph.Decimal(value))
+	}
+
+	manager.Mutex.Lock()
+	defer manager.Mutex.Unlock()
+	if err != nil {
+		// Если вычислитель уже освобожден (например, пропал),
+		// то операция возвращается в число готовых к выдаче
+		job.Status = OperationDispatched
+		if solver, ok := manager.SolverInfoMap[message.SolverName]; !ok || solver.operation != job {
+			graph.Release(job)
+			manager.TaskAvailable.Broadcast()
 		}
+		return err
+	}
+	if !failed {
+		graph.SetResult(job, value)
+	}
+	if manager.TaskGraphMap[graph.TaskID] == graph {
+		delete(manager.TaskGraphMap, graph.TaskID)
+	}
+	manager.freeSolver(message.SolverName)
 
-		// Записываем в словарь о том что вычислитель свободен
-		e.Manager.Mutex.Lock()
-		solver := e.Manager.SolverInfoMap[message.SolverName]
-		solver.InfoString = "Free"
-		solver.SolvingNowExpression = "None"
-		e.Manager.SolverInfoMap[message.SolverName] = solver
-		e.Manager.Mutex.Unlock()
+	log.Println("[OK]: Get operation result from solver successful")
+	return nil
+}
 
-		w.WriteHeader(http.StatusOK)
-		log.Println("[OK]: Get result from solver successful")
+/*
+FinishTask записывает в базу данных результат отданной вычислителю
+задачи с конечным статусом 3 (успешно посчитано) или 4 (ошибка
+вычисления) и сообщает о завершении задачи клиентам, которые ее ждут,
+подписчикам событий и на адрес клиента, если он указан в задаче. Если
+результат не удалось записать, то задача завершается со статусом 4.
+Если задача уже не в статусе 2, то результат пропускается
+
+Parameters:
+
+	int: Статус задачи
+	int: Номер задачи
+	string: Результат или текст ошибки
+	string: Десятичная запись точного результата
+
+Returns:
+
+	error: Ошибка базы данных
+*/
+func (manager *MessageManager) FinishTask(status int, id int, result string, decimal string) error {
+	finished, err := manager.DbConnection.FinishTaskFromID(status, id, result, decimal, time.Now())
+
+	// Если база данных не принимает результат, то повторная отправка
+	// ничего не изменит, поэтому задача завершается с ошибкой
+	if err != nil && status == 3 && isDataError(err) {
+		log.Printf("[ERROR]: Result of task %v can not be stored: %v", id, err)
+		status, result, decimal = 4, "Result can not be stored", ""
+		finished, err = manager.DbConnection.FinishTaskFromID(status, id, result, decimal, time.Now())
+	}
+	if err != nil {
+		return err
+	}
+
+	// Задачу уже завершили или отменили, сообщать не о чем
+	if !finished {
+		log.Printf("[INFO]: Task %v is not dispatched, result is ignored", id)
+		return nil
+	}
+	manager.TaskWaiters.Notify(id)
+
+	eventType := EventTaskCompleted
+	if status == 4 {
+		eventType = EventTaskFailed
+	}
+	manager.EventBus.PublishTask(eventType, TaskJSON{ID: id, Status: status, Result: result})
+	manager.sendWebhook(eventType, id)
+	return nil
+}
+
+/*
+sendWebhook отправляет завершенную задачу на ее CallbackURL.
+Адрес хранится в базе данных вместе с задачей, поэтому
+задача читается из базы уже с итоговым статусом и результатом.
+Если задачу успели отменить, то результат не отправляется,
+о ней уже сообщила отмена
+*/
+func (manager *MessageManager) sendWebhook(eventType string, id int) {
+	task, err := manager.DbConnection.GetTaskFromID(id)
+	if err != nil {
+		log.Println("[ERROR]: Can not read task for webhook: " + err.Error())
+		return
+	}
+	if task.Status == 5 && eventType != EventTaskCancelled {
+		return
+	}
+	manager.Webhooks.Enqueue(eventType, task)
+}
+
+/*
+CancelTask отменяет задачу, если она еще не посчитана. Если задача
+считается в режиме операций, то ее граф удаляется, а вычислителям,
+которые считают ее выражение или операцию, при следующем
+рукопожатии (или сразу в поток gRPC) передается отмена
+
+Returns:
+
+	bool: true, если задача была отменена
+	error: Ошибка базы данных
+*/
+func (manager *MessageManager) CancelTask(id int) (bool, error) {
+	cancelled, err := manager.DbConnection.CancelTaskFromID(id, time.Now())
+	if err != nil || !cancelled {
+		return false, err
+	}
+	manager.TaskWaiters.Notify(id)
+	manager.EventBus.PublishTask(EventTaskCancelled, TaskJSON{ID: id, Status: 5})
+	manager.sendWebhook(EventTaskCancelled, id)
+
+	manager.Mutex.Lock()
+	defer manager.Mutex.Unlock()
+
+	delete(manager.TaskGraphMap, id)
+	for _, solver := range manager.SolverInfoMap {
+		if solver.taskID == id || solver.graph != nil && solver.graph.TaskID == id {
+			solver.cancelTaskID = id
+			// Вычислителю, подключенному по gRPC, отмена
+			// отправляется сразу, не дожидаясь рукопожатия
+			if solver.cancelled != nil {
+				select {
+				case solver.cancelled <- id:
+				default:
+				}
+			}
+		}
+	}
+	return true, nil
+}
+
+/*
+registerSolver регистрирует вычислитель, если его еще нет в системе,
+и сообщает подписчикам о новом вычислителе. О вычислителе, который
+пропадал и снова прислал запрос, подписчикам тоже сообщается
+*/
+func (manager *MessageManager) registerSolver(solverName string) {
+	manager.Mutex.Lock()
+	defer manager.Mutex.Unlock()
+
+	solver, ok := manager.SolverInfoMap[solverName]
+	if !ok {
+		manager.SolverInfoMap[solverName] = &Solver{
+			SolverName:           solverName,
+			SolvingNowExpression: "None",
+			LastPing:             time.Now(),
+			InfoString:           "Registered",
+		}
+		manager.EventBus.PublishSolver(EventSolverRegistered, solverName)
+		return
+	}
+	if solver.lost {
+		solver.lost = false
+		solver.LastPing = time.Now()
+		manager.EventBus.PublishSolver(EventSolverRegistered, solverName)
+	}
+}
+
+/*
+freeSolver записывает в словарь о том что вычислитель свободен.
+Вызывается под мутексом менеджера
+*/
+func (manager *MessageManager) freeSolver(solverName string) {
+	solver, ok := manager.SolverInfoMap[solverName]
+	if !ok {
+		return
 	}
+	solver.InfoString = "Free"
+	solver.SolvingNowExpression = "None"
+	solver.taskID = 0
+	solver.graph = nil
+	solver.operation = nil
 }
 
 /*
@@ -422,11 +1989,14 @@ func (e *GetListOfSolvers) getExecutorRoute() string {
 func (e *GetListOfSolvers) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Создаем список вычислителей, и заполняем его
-		// из словаря с информацией о вычислителях из менеджера
-		solvers := make([]Solver, 0)
+		// из словаря с информацией о вычислителях из менеджера.
+		// Словарь меняют другие запросы, поэтому копируем его под мутексом
+		e.Manager.Mutex.Lock()
+		solvers := make([]Solver, 0, len(e.Manager.SolverInfoMap))
 		for _, val := range e.Manager.SolverInfoMap {
 			solvers = append(solvers, *val)
 		}
+		e.Manager.Mutex.Unlock()
 
 		// Конвертируем отклик в json-отклик
 		jsonResponse, err := json.Marshal(solvers)
@@ -478,26 +2048,203 @@ func (e *GetHandShake) getExecutorHandler() func(http.ResponseWriter, *http.Requ
 			return
 		}
 
-		log.Printf("[MESSAGE]: Solver name: %v", message.SolverName)
-
-		// Проверяем зарегистрирован ли вычислитель в системе
-		if _, ok := e.Manager.SolverInfoMap[message.SolverName]; !ok {
-			// Если нет, то регистрируем
-			e.Manager.Mutex.Lock()
-			e.Manager.SolverInfoMap[message.SolverName] = &Solver{
-				SolverName:           message.SolverName,
-				SolvingNowExpression: "None",
-				LastPing:             time.Now(),
-				InfoString:           "Registered",
-			}
-			e.Manager.Mutex.Unlock()
-		}
+		// Регистрируем вычислитель, если его еще нет в системе
+		e.Manager.registerSolver(message.SolverName)
 
-		// Записываем в словарь время рукопожатия
+		// Записываем в словарь время рукопожатия и забираем
+		// отмену задачи, которую считает вычислитель, если она есть
 		e.Manager.Mutex.Lock()
 		solver := e.Manager.SolverInfoMap[message.SolverName]
 		solver.LastPing = time.Now()
+		response := HandShakeResponseJSON{CancelTaskID: solver.cancelTaskID}
+		solver.cancelTaskID = 0
 		e.Manager.SolverInfoMap[message.SolverName] = solver
 		e.Manager.Mutex.Unlock()
+
+		// Конвертируем отклик в json-отклик
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			http.Error(w, "[ERROR]: GetHandShake Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: GetHandShake Can not encoding to JSON: " + err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+		if response.CancelTaskID != 0 {
+			log.Printf("[INFO]: Cancellation of task %v was sent to solver %v", response.CancelTaskID, message.SolverName)
+		}
+	}
+}
+
+/*
+LookupSubresult принимает запрос вычислителя с ключом
+операции и возвращает ее результат, если какой то
+вычислитель уже считал такую операцию
+*/
+type LookupSubresult struct {
+	Manager *MessageManager
+}
+
+func NewLookupSubresult(manager *MessageManager) *LookupSubresult {
+	return &LookupSubresult{
+		Manager: manager,
+	}
+}
+
+func (e *LookupSubresult) getExecutorRoute() string {
+	return "/lookupSubresult"
+}
+
+func (e *LookupSubresult) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Декодируем тело запроса в JSON нужной нам структуры
+		var message SubresultJSON
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&message)
+		if err != nil {
+			http.Error(w, "[ERROR]: LookupSubresult Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: LookupSubresult Decoding JSON was failed: " + err.Error())
+			return
+		}
+
+		// Ищем результат операции по ключу
+		message.Result, message.Found = e.Manager.Subresults.Get(message.Key)
+
+		// Конвертируем отклик в json-отклик
+		jsonResponse, err := json.Marshal(message)
+		if err != nil {
+			http.Error(w, "[ERROR]: LookupSubresult Can not encoding to JSON: "+err.Error(), http.StatusInternalServerError)
+			log.Println("[ERROR]: LookupSubresult Can not encoding to JSON: " + err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
+
+/*
+StoreSubresult принимает запрос вычислителя с ключом
+операции и ее результатом и сохраняет результат, что бы
+другие вычислители не считали такую же операцию заново
+*/
+type StoreSubresult struct {
+	Manager *MessageManager
+}
+
+func NewStoreSubresult(manager *MessageManager) *StoreSubresult {
+	return &StoreSubresult{
+		Manager: manager,
+	}
+}
+
+func (e *StoreSubresult) getExecutorRoute() string {
+	return "/storeSubresult"
+}
+
+func (e *StoreSubresult) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Декодируем тело запроса в JSON нужной нам структуры
+		var message SubresultJSON
+		decoder := json.NewDecoder(r.Body)
+		err := decoder.Decode(&message)
+		if err != nil {
+			http.Error(w, "[ERROR]: StoreSubresult Decoding JSON was failed: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: StoreSubresult Decoding JSON was failed: " + err.Error())
+			return
+		}
+		if message.Key == "" || message.Result == "" {
+			http.Error(w, "[ERROR]: StoreSubresult Empty key or result", http.StatusBadRequest)
+			log.Println("[ERROR]: StoreSubresult Empty key or result")
+			return
+		}
+
+		// Результат должен быть числом режима из ключа операции,
+		// иначе его получат вычислители других задач
+		err = checkSubresult(message.Key, message.Result)
+		if err != nil {
+			http.Error(w, "[ERROR]: StoreSubresult Invalid result: "+err.Error(), http.StatusBadRequest)
+			log.Println("[ERROR]: StoreSubresult Invalid result: " + err.Error())
+			return
+		}
+
+		// Когда хранилище заполнено, из него удаляется
+		// результат, который дольше всех не запрашивали
+		e.Manager.Subresults.Put(message.Key, message.Result)
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+/*
+eventsHeartbeat период, с которым клиенту событий отправляется
+комментарий, что бы прокси не закрывали тихое соединение
+*/
+const eventsHeartbeat = 15 * time.Second
+
+/*
+Events отправляет клиенту поток событий (Server-Sent Events) об
+изменении задач и вычислителей: создание, выдача вычислителю,
+успешное и неудачное завершение и отмена задачи, регистрация
+и пропажа вычислителя. Имя события в потоке это его тип,
+а данные это EventJSON
+*/
+type Events struct {
+	Manager *MessageManager
+}
+
+func NewEvents(manager *MessageManager) *Events {
+	return &Events{
+		Manager: manager,
+	}
+}
+
+func (e *Events) getExecutorRoute() string {
+	return "/events"
+}
+
+func (e *Events) getExecutorHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "[ERROR]: Events Streaming is not supported", http.StatusInternalServerError)
+			log.Println("[ERROR]: Events Streaming is not supported")
+			return
+		}
+
+		events, unsubscribe := e.Manager.EventBus.Subscribe()
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, ": connected\n\n")
+		flusher.Flush()
+		log.Println("[OK]: Events Client subscribed to events")
+
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event := <-events:
+				data, err := json.Marshal(event)
+				if err != nil {
+					log.Println("[ERROR]: Events Can not encoding to JSON: " + err.Error())
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			case <-r.Context().Done():
+				log.Println("[INFO]: Events Client unsubscribed from events")
+				return
+			}
+			flusher.Flush()
+		}
 	}
 }
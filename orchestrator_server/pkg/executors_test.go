@@ -0,0 +1,137 @@
+package pkg
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSameRequest(t *testing.T) {
+	task := TaskJSON{
+		Expression:         "a*b",
+		NumericMode:        "rational",
+		Precision:          5,
+		Variables:          map[string]float64{"a": 2, "b": 3},
+		CallbackURL:        "http://example.com/hook",
+		SimulateCachedTime: true,
+	}
+	same := ExpressionRequestJSON{
+		Expression:         "a*b",
+		NumericMode:        "rational",
+		Precision:          5,
+		Variables:          map[string]float64{"b": 3, "a": 2},
+		CallbackURL:        "http://example.com/hook",
+		SimulateCachedTime: true,
+		TimeToSend:         time.Now(),
+	}
+
+	tests := []struct {
+		name   string
+		change func(message *ExpressionRequestJSON)
+		same   bool
+	}{
+		{"same", func(message *ExpressionRequestJSON) {}, true},
+		{"other send time", func(message *ExpressionRequestJSON) { message.TimeToSend = time.Time{} }, true},
+		{"expression", func(message *ExpressionRequestJSON) { message.Expression = "a+b" }, false},
+		{"mode", func(message *ExpressionRequestJSON) { message.NumericMode = "float" }, false},
+		{"precision", func(message *ExpressionRequestJSON) { message.Precision = 6 }, false},
+		{"variable value", func(message *ExpressionRequestJSON) { message.Variables = map[string]float64{"a": 2, "b": 4} }, false},
+		{"variable name", func(message *ExpressionRequestJSON) { message.Variables = map[string]float64{"a": 2, "c": 3} }, false},
+		{"fewer variables", func(message *ExpressionRequestJSON) { message.Variables = map[string]float64{"a": 2} }, false},
+		{"callback", func(message *ExpressionRequestJSON) { message.CallbackURL = "" }, false},
+		{"simulate", func(message *ExpressionRequestJSON) { message.SimulateCachedTime = false }, false},
+	}
+
+	for _, test := range tests {
+		message := same
+		test.change(&message)
+		if res := sameRequest(task, message); res != test.same {
+			t.Errorf("sameRequest with %v changed = %v, want %v", test.name, res, test.same)
+		}
+	}
+}
+
+func TestParseTaskFilter(t *testing.T) {
+	from := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	to := time.Date(2024, 2, 3, 4, 5, 6, 0, time.FixedZone("", 3*60*60))
+	cursor, err := encodeTaskCursor(&TaskCursor{Value: "3", ID: 42})
+	if err != nil {
+		t.Fatalf("encodeTaskCursor returned %v", err)
+	}
+
+	tests := []struct {
+		query  string
+		filter TaskFilter
+	}{
+		{"", TaskFilter{Sort: "id", Limit: defaultTaskListLimit}},
+		{"status=1,%202&expression=2%2B2", TaskFilter{Statuses: []int{1, 2}, Expression: "2+2", Sort: "id", Limit: defaultTaskListLimit}},
+		{"from=2024-01-02T03:04:05Z&to=2024-02-03T04:05:06%2B03:00", TaskFilter{From: from, To: to, Sort: "id", Limit: defaultTaskListLimit}},
+		{"sort=-endTime&limit=1000", TaskFilter{Sort: "endTime", Descending: true, Limit: 1000}},
+		{"sort=status&limit=1&cursor=" + cursor, TaskFilter{Sort: "status", Limit: 1, Cursor: &TaskCursor{Value: "3", ID: 42}}},
+	}
+
+	for _, test := range tests {
+		query, _ := url.ParseQuery(test.query)
+		filter, err := parseTaskFilter(query)
+		if err != nil {
+			t.Errorf("parseTaskFilter(%q) returned %v", test.query, err)
+			continue
+		}
+		if !filter.From.Equal(test.filter.From) || !filter.To.Equal(test.filter.To) {
+			t.Errorf("parseTaskFilter(%q) times = %v, %v, want %v, %v",
+				test.query, filter.From, filter.To, test.filter.From, test.filter.To)
+		}
+		filter.From, filter.To = test.filter.From, test.filter.To
+		if !reflect.DeepEqual(filter, test.filter) {
+			t.Errorf("parseTaskFilter(%q) = %+v, want %+v", test.query, filter, test.filter)
+		}
+	}
+}
+
+func TestParseTaskFilterErrors(t *testing.T) {
+	tests := []string{
+		"status=done",
+		"status=1,",
+		"from=yesterday",
+		"to=2024-01-02",
+		"sort=expression",
+		"sort=-",
+		"limit=0",
+		"limit=1001",
+		"limit=ten",
+		"cursor=!!!",
+		"cursor=bm90IGpzb24",
+	}
+
+	for _, test := range tests {
+		query, _ := url.ParseQuery(test)
+		if filter, err := parseTaskFilter(query); err == nil {
+			t.Errorf("parseTaskFilter(%q) = %+v, want error", test, filter)
+		}
+	}
+}
+
+func TestTaskCursor(t *testing.T) {
+	tests := []TaskCursor{
+		{Value: "42", ID: 42},
+		{Value: "2024-01-02T03:04:05.123456Z", ID: 7},
+		{Value: "", ID: 0},
+		{Value: "значение с пробелами/+=", ID: 1},
+	}
+
+	for _, test := range tests {
+		text, err := encodeTaskCursor(&test)
+		if err != nil {
+			t.Errorf("encodeTaskCursor(%+v) returned %v", test, err)
+			continue
+		}
+		if url.QueryEscape(text) != text {
+			t.Errorf("encodeTaskCursor(%+v) = %q, not safe for URL", test, text)
+		}
+		cursor, err := decodeTaskCursor(text)
+		if err != nil || *cursor != test {
+			t.Errorf("decodeTaskCursor(%q) = %+v, %v, want %+v", text, cursor, err, test)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"solverapi"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+SolverServer принимает вычислители по gRPC. Это тот же протокол,
+что и у исполнителей GetReadyTaskToSolving, SetResultOfSolving
+и GetHandShake, но каждый вычислитель держит один двунаправленный
+поток: в нем идут рукопожатия, выдача задач, прогресс, результаты
+и отмены задач. Описание протокола лежит в solverapi/solver.proto
+*/
+type SolverServer struct {
+	solverapi.UnimplementedSolverServiceServer
+	Manager *MessageManager
+	Port    string
+}
+
+/*
+NewSolverServer возвращает ссылку на gRPC сервер вычислителей
+
+Parameters:
+
+	*MessageManager: Менеджер оркестратора
+	string: Порт сервера
+
+Returns:
+
+	*SolverServer: gRPC сервер вычислителей
+*/
+func NewSolverServer(manager *MessageManager, port string) *SolverServer {
+	return &SolverServer{
+		Manager: manager,
+		Port:    port,
+	}
+}
+
+/*
+Run запускает gRPC сервер в отдельной горутине
+*/
+func (s *SolverServer) Run() {
+	listener, err := net.Listen("tcp", ":"+s.Port)
+	if err != nil {
+		log.Fatalln(err)
+		return
+	}
+
+	server := grpc.NewServer()
+	solverapi.RegisterSolverServiceServer(server, s)
+
+	go func() {
+		log.Printf("[RUN] gRPC server begin run. Port: %v\n", s.Port)
+		if err := server.Serve(listener); err != nil {
+			log.Fatalln(err)
+			return
+		}
+	}()
+}
+
+/*
+Connect обслуживает поток одного вычислителя. Первым сообщением
+вычислитель присылает Hello с именем и режимом работы. После
+TaskRequest оркестратор отправляет задачу, как только она появится.
+Когда поток рвется, вычислитель считается пропавшим, но его задача
+остается за ним, пока демон рукопожатий не вернет ее в обработку:
+если вычислитель успеет переподключиться, то он отправит результат
+повторно в новом потоке
+
+Parameters:
+
+	solverapi.SolverService_ConnectServer: Поток вычислителя
+
+Returns:
+
+	error: Причина завершения потока
+*/
+func (s *SolverServer) Connect(stream solverapi.SolverService_ConnectServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	hello := first.GetHello()
+	if hello == nil || hello.SolverName == "" {
+		return status.Error(codes.InvalidArgument, "First message must be hello with solver name")
+	}
+	solverName := hello.SolverName
+
+	// Регистрируем вычислитель и заводим канал,
+	// через который CancelTask отправляет отмены в поток
+	s.Manager.registerSolver(solverName)
+	cancelled := make(chan int, 16)
+	s.Manager.Mutex.Lock()
+	solver := s.Manager.SolverInfoMap[solverName]
+	solver.LastPing = time.Now()
+	solver.cancelled = cancelled
+	s.Manager.Mutex.Unlock()
+	log.Printf("[OK]: Solver %v connected by gRPC", solverName)
+
+	defer s.disconnect(solverName, cancelled)
+
+	// Сообщения вычислителя читаем в отдельной горутине,
+	// что бы в основном цикле ждать их вместе с задачами и отменами
+	messages := make(chan *solverapi.SolverMessage)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			message, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case messages <- message:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+
+	// Свободному вычислителю задачу ищем, только когда он ее запросил
+	// или когда появилась новая задача, а при ошибке базы данных
+	// через секунду. Пока задачи нет, ждем сигнал вместе с сообщениями потока
+	waiting, search := false, false
+	var available chan struct{}
+	var retry <-chan time.Time
+	for {
+		if waiting && search {
+			search, retry = false, nil
+			available = s.Manager.TaskAvailable.Wait()
+			task, err := s.dispatch(solverName, hello.Mode)
+			if err != nil {
+				log.Println("[ERROR]: Can not get task to solver: " + err.Error())
+				retry = time.After(1 * time.Second)
+			}
+			if task != nil {
+				if err = stream.Send(taskMessage(task)); err != nil {
+					return err
+				}
+				waiting, available = false, nil
+			}
+		}
+
+		select {
+		case message := <-messages:
+			s.ping(solverName)
+			switch {
+			case message.GetTaskRequest() != nil:
+				// Свободный вычислитель ничего не считает, поэтому задача,
+				// оставшаяся за ним с прошлого потока, возвращается в обработку
+				s.release(solverName)
+				waiting, search = true, true
+			case message.GetProgress() != nil:
+				s.progress(solverName, message.GetProgress())
+			case message.GetResult() != nil:
+				// Если результат не записан, рвем поток: задача остается
+				// за вычислителем, он переподключится и отправит результат повторно
+				if err := s.Manager.SaveResult(resultFromMessage(solverName, message.GetResult())); err != nil {
+					log.Println("[ERROR]: Can not save result of solver: " + err.Error())
+					return status.Error(codes.Unavailable, err.Error())
+				}
+			}
+		case id := <-cancelled:
+			cancel := &solverapi.OrchestratorMessage{
+				Message: &solverapi.OrchestratorMessage_Cancel{
+					Cancel: &solverapi.Cancel{TaskId: int64(id)},
+				},
+			}
+			if err := stream.Send(cancel); err != nil {
+				return err
+			}
+		case <-available:
+			search = true
+		case <-retry:
+			search = true
+		case err := <-errs:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+/*
+dispatch выдает вычислителю выражение или операцию в зависимости от режима
+*/
+func (s *SolverServer) dispatch(solverName string, mode string) (*TaskToSendToSolver, error) {
+	if mode == OperationMode {
+		return s.Manager.DispatchOperation(solverName)
+	}
+	return s.Manager.DispatchExpression(solverName)
+}
+
+/*
+ping записывает время последнего сообщения вычислителя
+*/
+func (s *SolverServer) ping(solverName string) {
+	s.Manager.registerSolver(solverName)
+
+	s.Manager.Mutex.Lock()
+	defer s.Manager.Mutex.Unlock()
+	s.Manager.SolverInfoMap[solverName].LastPing = time.Now()
+}
+
+/*
+progress записывает прогресс задачи в информационную строку вычислителя
+*/
+func (s *SolverServer) progress(solverName string, progress *solverapi.Progress) {
+	s.Manager.Mutex.Lock()
+	defer s.Manager.Mutex.Unlock()
+
+	solver := s.Manager.SolverInfoMap[solverName]
+	if solver.taskID == 0 && solver.operation == nil {
+		return
+	}
+	if progress.TotalOperations > 0 {
+		solver.InfoString = fmt.Sprintf("Working (%v/%v operations)",
+			progress.DoneOperations, progress.TotalOperations)
+	} else {
+		solver.InfoString = fmt.Sprintf("Working (%v operations)", progress.DoneOperations)
+	}
+}
+
+/*
+release возвращает в обработку задачу или операцию,
+которая осталась за вычислителем с прошлого потока
+*/
+func (s *SolverServer) release(solverName string) {
+	s.Manager.Mutex.Lock()
+	id := s.Manager.releaseSolver(s.Manager.SolverInfoMap[solverName])
+	s.Manager.Mutex.Unlock()
+	s.Manager.requeueTask(solverName, id)
+}
+
+/*
+disconnect сообщает подписчикам о пропаже вычислителя, поток
+которого оборвался. Задача остается за вычислителем, а время
+последнего сообщения отсчитывается от обрыва потока, поэтому
+демон рукопожатий вернет задачу в обработку, если вычислитель
+не переподключится за две секунды
+*/
+func (s *SolverServer) disconnect(solverName string, cancelled chan int) {
+	s.Manager.Mutex.Lock()
+	defer s.Manager.Mutex.Unlock()
+
+	solver := s.Manager.SolverInfoMap[solverName]
+	// Вычислитель мог уже переподключиться новым потоком
+	if solver.cancelled != cancelled {
+		return
+	}
+	solver.cancelled = nil
+	solver.InfoString = "The server is not working"
+	solver.LastPing = time.Now()
+	if !solver.lost {
+		solver.lost = true
+		s.Manager.EventBus.PublishSolver(EventSolverLost, solverName)
+	}
+	log.Printf("[INFO]: Solver %v disconnected from gRPC", solverName)
+}
+
+/*
+taskMessage переводит задачу для вычислителя в сообщение потока
+*/
+func taskMessage(task *TaskToSendToSolver) *solverapi.OrchestratorMessage {
+	times := make(map[string]int32, len(task.Times))
+	for key, val := range task.Times {
+		times[key] = int32(val)
+	}
+	return &solverapi.OrchestratorMessage{
+		Message: &solverapi.OrchestratorMessage_Task{
+			Task: &solverapi.Task{
+				TaskId:      int64(task.TaskID),
+				Expression:  task.Expression,
+				Variables:   task.Variables,
+				Times:       times,
+				NumericMode: task.NumericMode,
+				Precision:   int32(task.Precision),
+				OperationId: int64(task.OperationID),
+				Args:        task.Args,
+				Operation:   task.Operation,
+				Time:        int32(task.Time),
+			},
+		},
+	}
+}
+
+/*
+resultFromMessage переводит результат из потока в результат вычислителя
+*/
+func resultFromMessage(solverName string, result *solverapi.Result) ResultFromSolver {
+	return ResultFromSolver{
+		SolverName:  solverName,
+		Expression:  result.Expression,
+		Result:      result.Result,
+		Decimal:     result.Decimal,
+		Status:      int(result.Status),
+		TaskID:      int(result.TaskId),
+		OperationID: int(result.OperationId),
+	}
+}
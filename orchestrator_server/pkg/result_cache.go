@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expression"
+	"log"
+	"sort"
+	"time"
+)
+
+/*
+resultCacheKey описывает все, от чего зависит результат задачи.
+Хэш этой структуры записывается в HashID задачи и по нему ищутся
+уже посчитанные результаты. Точность и время выполнения операций
+в ключ не входят: от них результат не зависит, десятичная запись
+точного результата пересчитывается с точностью новой задачи
+*/
+type resultCacheKey struct {
+	Expression  string          `json:"expression"`
+	NumericMode string          `json:"numericMode"`
+	Variables   []cacheVariable `json:"variables"`
+}
+
+/*
+cacheVariable описывает значение одной переменной в ключе кэша
+*/
+type cacheVariable struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+/*
+ResultCacheHash возвращает ключ кэша результатов для выражения:
+выражение приводится к каноничному виду, поэтому "2+2*2" и
+" 2 + (2*2) " имеют одинаковый ключ, а к нему добавляются режим
+вычисления и отсортированные по имени значения переменных
+
+Parameters:
+
+	string: Выражение
+	string: Режим вычисления
+	map[string]float64: Значения переменных
+
+Returns:
+
+	string: Ключ кэша в шестнадцатеричной записи
+	error: Ошибка разбора выражения
+*/
+func ResultCacheHash(expr string, mode string, variables map[string]float64) (string, error) {
+	canonical, err := expression.Normalize(expr)
+	if err != nil {
+		return "", err
+	}
+
+	// Пустой словарь и его отсутствие дают одинаковый ключ
+	key := resultCacheKey{
+		Expression:  canonical,
+		NumericMode: mode,
+		Variables:   make([]cacheVariable, 0, len(variables)),
+	}
+	for name, value := range variables {
+		key.Variables = append(key.Variables, cacheVariable{Name: name, Value: value})
+	}
+	sort.Slice(key.Variables, func(i, j int) bool {
+		return key.Variables[i].Name < key.Variables[j].Name
+	})
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+/*
+FinishCachedTask дожидается предсказанного времени вычисления
+задачи, результат которой взят из кэша, и отмечает ее посчитанной.
+Используется, если клиент попросил имитировать время вычисления.
+Результат и время окончания уже записаны в задачу в базе данных,
+поэтому после перезапуска оркестратора такие задачи завершает
+ResumeCachedTasks
+*/
+func (manager *MessageManager) FinishCachedTask(task TaskJSON, duration time.Duration) {
+	time.AfterFunc(duration, func() {
+		err := manager.FinishTask(3, task.ID, task.Result, task.ResultDecimal)
+		if err != nil {
+			log.Println("[ERROR]: Can not finish cached task: " + err.Error())
+			return
+		}
+		log.Printf("[OK]: Cached task %v was finished", task.ID)
+	})
+}
+
+/*
+ResumeCachedTasks находит задачи с результатом из кэша, которые
+не успели завершиться до перезапуска оркестратора (статус 2),
+и завершает их в записанное время окончания или сразу, если оно прошло
+
+Returns:
+
+	error: Ошибка базы данных
+*/
+func (manager *MessageManager) ResumeCachedTasks() error {
+	tasks, err := manager.DbConnection.GetTasksFromStatus(2)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if task.Cached {
+			manager.FinishCachedTask(task, time.Until(task.EndTime))
+		}
+	}
+	return nil
+}
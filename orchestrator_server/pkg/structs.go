@@ -6,27 +6,63 @@ import (
 
 /*
 TaskJSON описывает структуру задачи,
-хранящейся в таблице базы данных
+хранящейся в таблице базы данных.
+EndTime это предсказанное время окончания вычисления,
+которое считается при выдаче задачи вычислителю,
+ActualEndTime это фактическое время окончания вычисления.
+NumericMode режим вычисления (float или rational), в режиме
+rational Result это точная дробь, а ResultDecimal ее
+десятичная запись с Precision знаками после запятой.
+Variables хранит значения переменных выражения.
+HashID это ключ кэша результатов, Cached выставляется,
+если результат взят из уже посчитанной задачи.
+IdempotencyKey это ключ, с которым клиент отправил задачу,
+CallbackURL это адрес, на который отправляется результат задачи,
+SimulateCachedTime выставляется, если задача создана с имитацией
+времени вычисления результата из кэша
 */
 type TaskJSON struct {
-	ID         int       `json:"id"`
-	Expression string    `json:"expression"`
-	HashID     string    `json:"hashID"`
-	Status     int       `json:"status"`
-	Result     string    `json:"result"`
-	BeginTime  time.Time `json:"beginTime"`
-	EndTime    time.Time `json:"endTime"`
+	ID                 int                `json:"id"`
+	Expression         string             `json:"expression"`
+	HashID             string             `json:"hashID"`
+	Status             int                `json:"status"`
+	Result             string             `json:"result"`
+	BeginTime          time.Time          `json:"beginTime"`
+	EndTime            time.Time          `json:"endTime"`
+	ActualEndTime      time.Time          `json:"actualEndTime"`
+	NumericMode        string             `json:"numericMode"`
+	Precision          int                `json:"precision"`
+	ResultDecimal      string             `json:"resultDecimal"`
+	Variables          map[string]float64 `json:"variables"`
+	Cached             bool               `json:"cached"`
+	IdempotencyKey     string             `json:"idempotencyKey,omitempty"`
+	CallbackURL        string             `json:"callbackUrl,omitempty"`
+	SimulateCachedTime bool               `json:"simulateCachedTime,omitempty"`
 }
 
 /*
 TaskToSendToSolver описывает структуру задачи,
 которая будет отправлена вычислителю, если
 он задачу запросит. Включает в себя само выражение
-и словарь со временем выполнения для операций
+и словарь со временем выполнения для операций, а так же
+номер задачи, который вычислитель возвращает вместе с ответом.
+Вычислителю, работающему в режиме операций, вместо
+целого выражения отдается одна операция графа задачи:
+номер задачи и операции, ее аргументы, знак операции
+или имя функции и время ее выполнения в секундах. Аргументы передаются
+строкой, что бы в точном режиме вычисления передать дробь
 */
 type TaskToSendToSolver struct {
-	Expression string         `json:"expression"`
-	Times      map[string]int `json:"times"`
+	Expression  string             `json:"expression"`
+	Variables   map[string]float64 `json:"variables"`
+	Times       map[string]int     `json:"times"`
+	NumericMode string             `json:"numericMode"`
+	Precision   int                `json:"precision"`
+	TaskID      int                `json:"taskId"`
+	OperationID int                `json:"operationId"`
+	Args        []string           `json:"args"`
+	Operation   string             `json:"operation"`
+	Time        int                `json:"time"`
 }
 
 /*
@@ -35,13 +71,20 @@ ResultFromSolver описывает JSON с результатом
 иметь вычислитель, желающий отправить ответ.
 Включает в себя выражение, ответ и сообщение с
 ошибками, комментарием от вычислителя и т. п.
-используется в исполнителе SetResultOfSolving
+используется в исполнителе SetResultOfSolving.
+В ответе указывается номер задачи, а если вычислитель
+считал одну операцию графа задачи, то и номер операции.
+В точном режиме вычисления Decimal содержит
+десятичную запись ответа
 */
 type ResultFromSolver struct {
-	SolverName string `json:"solverName"`
-	Expression string `json:"expression"`
-	Result     string `json:"result"`
-	Status     int    `json:"status"`
+	SolverName  string `json:"solverName"`
+	Expression  string `json:"expression"`
+	Result      string `json:"result"`
+	Decimal     string `json:"decimal"`
+	Status      int    `json:"status"`
+	TaskID      int    `json:"taskId"`
+	OperationID int    `json:"operationId"`
 }
 
 /*
@@ -59,11 +102,68 @@ ExpressionRequestJSON описывает JSON с выражением
 для вычисления. Такую структуру должен содержать запрос
 клиента, желающего добавить выражений в обработку.
 Запрос содержит само выражение и время его отправки
-на сервер
+на сервер, а так же необязательные режим вычисления
+(float по умолчанию или rational) и количество знаков
+после запятой для десятичной записи точного результата.
+Variables содержит значения переменных выражения, например
+для a*b+c это может быть {"a": 2, "b": 3, "c": 4}.
+Если такое выражение уже было посчитано, то задача сразу
+завершается результатом из кэша, а при SimulateCachedTime
+завершается через предсказанное время вычисления.
+IdempotencyKey (или заголовок Idempotency-Key) позволяет
+повторять запрос без создания второй задачи. На CallbackURL
+оркестратор отправит задачу, когда она завершится
 */
 type ExpressionRequestJSON struct {
-	Expression string    `json:"expression"`
-	TimeToSend time.Time `json:"timeToSend"`
+	Expression         string             `json:"expression"`
+	TimeToSend         time.Time          `json:"timeToSend"`
+	NumericMode        string             `json:"numericMode"`
+	Precision          int                `json:"precision"`
+	Variables          map[string]float64 `json:"variables"`
+	SimulateCachedTime bool               `json:"simulateCachedTime"`
+	IdempotencyKey     string             `json:"idempotencyKey"`
+	CallbackURL        string             `json:"callbackUrl"`
+}
+
+/*
+TaskIDJSON описывает JSON ответа на добавление выражения,
+содержит номер созданной задачи, по которому задачу можно
+получить через исполнитель GetTask
+*/
+type TaskIDJSON struct {
+	ID int `json:"id"`
+}
+
+/*
+BatchItemJSON описывает результат добавления одного выражения
+из запроса к исполнителю AddArithmeticExpressions: номер выражения
+в запросе и номер созданной задачи или ошибку разбора выражения
+*/
+type BatchItemJSON struct {
+	Index int                  `json:"index"`
+	ID    int                  `json:"id,omitempty"`
+	Error *ValidationErrorJSON `json:"error,omitempty"`
+}
+
+/*
+TaskIDsJSON описывает JSON со списком номеров задач, такую
+структуру должен содержать запрос к исполнителю GetListOfTasksFromIDs
+*/
+type TaskIDsJSON struct {
+	IDs []int `json:"ids"`
+}
+
+/*
+SubresultJSON описывает JSON с результатом подвыражения.
+Вычислитель перед выполнением операции ищет ее результат
+по ключу в исполнителе LookupSubresult, а посчитав операцию,
+сохраняет результат в исполнителе StoreSubresult.
+Found выставляется в ответе, если результат найден
+*/
+type SubresultJSON struct {
+	Key    string `json:"key"`
+	Result string `json:"result"`
+	Found  bool   `json:"found"`
 }
 
 /*
@@ -72,22 +172,148 @@ Solver описывает вычислителя и информацию о не
 последний раз, когда вычислитель давал о себе знать и
 информационную строку от вычислителя. Массив таких структур
 используется для создания ответа клиенту, на запрос
-об информации о вычислителях в исполнителе GetListOfSolvers
+об информации о вычислителях в исполнителе GetListOfSolvers.
+Если вычислитель считает выражение целиком, то в структуре
+запоминается номер задачи, а если одну операцию графа задачи,
+то граф и эта операция. Номер отмененной задачи, которую
+считает вычислитель, хранится до его следующего рукопожатия.
+У вычислителя, подключенного по gRPC, есть канал, через который
+отмены сразу отправляются в его поток
 */
 type Solver struct {
 	SolverName           string    `json:"solverName"`
 	SolvingNowExpression string    `json:"solvingExpression"`
 	LastPing             time.Time `json:"lastPing"`
 	InfoString           string    `json:"infoString"`
+	taskID               int
+	cancelTaskID         int
+	cancelled            chan int
+	lost                 bool
+	graph                *TaskGraph
+	operation            *OperationJob
 }
 
 /*
 SolverRequestJSON описывает JSON запроса вычислителя
 на сервер. Такую структуру должен содержать запрос,
 для регулярного рукопожатия с сервером или для получения
-задачи. Содержит имя вычислителя, который совершает запрос.
+задачи. Содержит имя вычислителя, который совершает запрос,
+и режим его работы: ExpressionMode (по умолчанию) или OperationMode.
 Используется в исполнителе GetReadyTaskToSolving
 */
 type SolverRequestJSON struct {
 	SolverName string `json:"solverName"`
+	Mode       string `json:"mode"`
+}
+
+/*
+HandShakeResponseJSON описывает JSON ответа на рукопожатие
+вычислителя. Если задачу, которую считает вычислитель, отменили,
+то в ответе передается ее номер, и вычислитель прекращает вычисление
+*/
+type HandShakeResponseJSON struct {
+	CancelTaskID int `json:"cancelTaskId,omitempty"`
+}
+
+/*
+Режимы работы вычислителя. В режиме ExpressionMode
+вычислитель получает выражение целиком, в режиме
+OperationMode получает по одной операции графа задачи
+*/
+const (
+	ExpressionMode = "expression"
+	OperationMode  = "operation"
+)
+
+/*
+ValidationErrorJSON описывает JSON с ошибкой разбора выражения,
+который возвращается клиенту с кодом 400. Содержит текст ошибки,
+позицию ошибки в байтах от начала выражения, номер колонки
+(считая с единицы), лексему на этой позиции и причину ошибки
+*/
+type ValidationErrorJSON struct {
+	Error    string `json:"error"`
+	Position int    `json:"position"`
+	Column   int    `json:"column"`
+	Token    string `json:"token"`
+	Reason   string `json:"reason"`
+}
+
+/*
+ExplainNodeJSON описывает узел дерева выражения в ответе
+исполнителя ExplainExpression. У числа заполнено только
+значение, у переменной только ее имя, у операции знак
+операции и оба поддерева, у функции имя и аргументы
+*/
+type ExplainNodeJSON struct {
+	Operation string             `json:"operation,omitempty"`
+	Value     *float64           `json:"value,omitempty"`
+	Variable  string             `json:"variable,omitempty"`
+	Arguments []*ExplainNodeJSON `json:"arguments,omitempty"`
+	Left      *ExplainNodeJSON   `json:"left,omitempty"`
+	Right     *ExplainNodeJSON   `json:"right,omitempty"`
+}
+
+/*
+ExplainOperationJSON описывает одну операцию плана вычисления:
+саму операцию с операндами, время ее выполнения, а так же
+время начала и окончания от начала вычисления, все в секундах
+*/
+type ExplainOperationJSON struct {
+	Expression string `json:"expression"`
+	Operation  string `json:"operation"`
+	Duration   int    `json:"duration"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+}
+
+/*
+ExplainStageJSON описывает этап плана вычисления,
+то есть операции, которые вычислитель запустит одновременно
+*/
+type ExplainStageJSON struct {
+	Start      int                    `json:"start"`
+	Operations []ExplainOperationJSON `json:"operations"`
+}
+
+/*
+ExplainResponseJSON описывает ответ исполнителя ExplainExpression:
+дерево выражения в том виде, в котором его будет считать вычислитель,
+этапы параллельного вычисления, время выполнения операций
+и предсказанное общее время вычисления в секундах. Значения
+переменных на план не влияют, поэтому в ответе только их имена
+*/
+type ExplainResponseJSON struct {
+	Expression string             `json:"expression"`
+	Variables  []string           `json:"variables"`
+	Tree       *ExplainNodeJSON   `json:"tree"`
+	Stages     []ExplainStageJSON `json:"stages"`
+	Times      map[string]int     `json:"times"`
+	TotalTime  int                `json:"totalTime"`
+}
+
+/*
+EventJSON описывает событие об изменении задачи или
+вычислителя, которое исполнитель Events отправляет клиентам.
+Для событий задач заполняются номер, выражение, статус
+и результат задачи, для событий вычислителей его имя
+*/
+type EventJSON struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	TaskID     int       `json:"taskId,omitempty"`
+	Expression string    `json:"expression,omitempty"`
+	Status     int       `json:"status,omitempty"`
+	Result     string    `json:"result,omitempty"`
+	SolverName string    `json:"solverName,omitempty"`
+}
+
+/*
+WebhookPayloadJSON описывает тело запроса, который оркестратор
+отправляет на CallbackURL задачи после ее завершения: тип
+события (task.completed, task.failed или task.cancelled) и задачу
+*/
+type WebhookPayloadJSON struct {
+	Event string   `json:"event"`
+	Task  TaskJSON `json:"task"`
 }
@@ -0,0 +1,149 @@
+package pkg
+
+import (
+	"container/list"
+	"expression"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+/*
+maxSubresults ограничивает количество результатов
+подвыражений, которые хранит оркестратор
+*/
+const maxSubresults = 100000
+
+/*
+SubresultCache хранит результаты подвыражений, которые уже
+посчитали вычислители. Когда кэш заполнен, из него удаляется
+результат, который дольше всех не запрашивали (LRU). У кэша
+свой мутекс, потому что вычислители обращаются к нему на
+каждой операции
+*/
+type SubresultCache struct {
+	mutex    sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+/*
+subresultEntry элемент списка кэша подвыражений
+*/
+type subresultEntry struct {
+	key    string
+	result string
+}
+
+/*
+NewSubresultCache возвращает ссылку на пустой кэш подвыражений
+
+Parameters:
+
+	int: Максимальное количество результатов в кэше
+
+Returns:
+
+	*SubresultCache: Кэш подвыражений
+*/
+func NewSubresultCache(capacity int) *SubresultCache {
+	return &SubresultCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+/*
+Get возвращает результат подвыражения по ключу
+и отмечает его как недавно использованный
+
+Parameters:
+
+	string: Ключ операции
+
+Returns:
+
+	string: Результат операции
+	bool: Найден ли результат
+*/
+func (c *SubresultCache) Get(key string) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*subresultEntry).result, true
+}
+
+/*
+Put сохраняет результат подвыражения. Если кэш заполнен,
+то удаляется результат, который дольше всех не использовали
+
+Parameters:
+
+	string: Ключ операции
+	string: Результат операции
+*/
+func (c *SubresultCache) Put(key string, result string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		element.Value.(*subresultEntry).result = result
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&subresultEntry{key: key, result: result})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*subresultEntry).key)
+	}
+}
+
+/*
+Len возвращает количество результатов в кэше
+*/
+func (c *SubresultCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+/*
+checkSubresult проверяет, что результат подвыражения является
+числом режима из ключа операции, например ключ float:*(12,17)
+требует конечное число с плавающей точкой, а ключ rational:/(1,3)
+точную дробь
+
+Parameters:
+
+	string: Ключ операции
+	string: Результат операции
+
+Returns:
+
+	error: Ошибка, если результат не подходит к ключу
+*/
+func checkSubresult(key string, result string) error {
+	mode, _, found := strings.Cut(key, ":")
+	if !found || (mode != expression.FloatMode && mode != expression.RationalMode) {
+		return fmt.Errorf("Invalid subresult key: %v", key)
+	}
+
+	number, err := expression.ParseNumber(result, mode)
+	if err != nil {
+		return err
+	}
+	if mode == expression.FloatMode && (math.IsNaN(number.Float) || math.IsInf(number.Float, 0)) {
+		return fmt.Errorf("Result is not a finite number: %v", result)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"testing"
+)
+
+func TestSubresultCacheEviction(t *testing.T) {
+	cache := NewSubresultCache(2)
+	cache.Put("float:+(1,2)", "3")
+	cache.Put("float:+(2,2)", "4")
+
+	// Запрошенный результат становится самым новым,
+	// поэтому при переполнении удаляется другой
+	if _, ok := cache.Get("float:+(1,2)"); !ok {
+		t.Fatalf("Get(%q) did not find stored result", "float:+(1,2)")
+	}
+	cache.Put("float:+(3,2)", "5")
+
+	tests := []struct {
+		key    string
+		result string
+		found  bool
+	}{
+		{"float:+(1,2)", "3", true},
+		{"float:+(2,2)", "", false},
+		{"float:+(3,2)", "5", true},
+	}
+
+	for _, test := range tests {
+		result, found := cache.Get(test.key)
+		if result != test.result || found != test.found {
+			t.Errorf("Get(%q) = %v, %v, want %v, %v", test.key, result, found, test.result, test.found)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %v, want 2", cache.Len())
+	}
+
+	// Повторное сохранение не увеличивает кэш
+	cache.Put("float:+(3,2)", "5")
+	if cache.Len() != 2 {
+		t.Errorf("Len() after repeated Put = %v, want 2", cache.Len())
+	}
+}
+
+func TestCheckSubresult(t *testing.T) {
+	tests := []struct {
+		key    string
+		result string
+		valid  bool
+	}{
+		{"float:*(12,17)", "204", true},
+		{"float:/(1,3)", "0.3333333333333333", true},
+		{"float:*(1e308,10)", "+Inf", false},
+		{"float:sqrt(-1)", "NaN", false},
+		{"float:*(12,17)", "1/3", false},
+		{"float:*(12,17)", "abc", false},
+		{"rational:/(1,3)", "1/3", true},
+		{"rational:*(12,17)", "204", true},
+		{"rational:/(1,3)", "abc", false},
+		{"decimal:*(12,17)", "204", false},
+		{"*(12,17)", "204", false},
+	}
+
+	for _, test := range tests {
+		err := checkSubresult(test.key, test.result)
+		if (err == nil) != test.valid {
+			t.Errorf("checkSubresult(%q, %q) returned %v, want valid %v", test.key, test.result, err, test.valid)
+		}
+	}
+}
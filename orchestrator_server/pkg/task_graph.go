@@ -0,0 +1,229 @@
+package pkg
+
+import (
+	"expression"
+	"strings"
+)
+
+/*
+Статусы операции графа задачи
+*/
+const (
+	OperationWaiting    = iota // Ждет результаты своих операндов
+	OperationReady             // Все операнды известны, можно отдавать вычислителю
+	OperationDispatched        // Отдана вычислителю
+	OperationDone              // Посчитана
+	OperationFinishing         // Результат задачи записывается в базу данных
+)
+
+/*
+OperationJob описывает одну операцию или вызов функции графа
+задачи. Операция становится готовой к выдаче вычислителю, когда
+известны все ее аргументы, результат операции передается
+в операцию-родителя, которая его ждет
+*/
+type OperationJob struct {
+	ID         int
+	Operation  string
+	Args       []expression.Number
+	Status     int
+	SolverName string
+	pending    int
+	parent     *OperationJob
+	index      int
+}
+
+/*
+String возвращает операцию в виде строки, например 3*4 или pow(2,3)
+*/
+func (job *OperationJob) String() string {
+	if _, ok := expression.LookupFunction(job.Operation); ok {
+		args := make([]string, 0, len(job.Args))
+		for _, arg := range job.Args {
+			args = append(args, arg.String())
+		}
+		return job.Operation + "(" + strings.Join(args, ",") + ")"
+	}
+	return job.Args[0].String() + job.Operation + job.Args[1].String()
+}
+
+/*
+TaskGraph описывает задачу, разбитую на отдельные операции.
+Каждая операция может быть отдана своему вычислителю, поэтому
+одно длинное выражение могут считать сразу несколько вычислителей.
+Если выражение является числом, то операций в графе нет,
+а результат известен сразу. Граф хранит режим вычисления задачи
+и точность десятичной записи результата
+*/
+type TaskGraph struct {
+	TaskID      int
+	Expression  string
+	NumericMode string
+	Precision   int
+	Operations  []*OperationJob
+	Root        *OperationJob
+	Value       expression.Number
+}
+
+/*
+NewTaskGraph разбирает выражение задачи и строит граф операций.
+В дерево выражения подставляются значения переменных задачи,
+а цепочки ассоциативных операций перестраиваются,
+чтобы их можно было считать параллельно
+
+Parameters:
+
+	TaskJSON: Задача из базы данных
+	map[string]int: Время выполнения операций в секундах
+
+Returns:
+
+	*TaskGraph: Граф операций задачи
+	error: Ошибки разбора выражения
+*/
+func NewTaskGraph(task TaskJSON, times map[string]int) (*TaskGraph, error) {
+	tree, err := expression.ParseWithVariables(task.Expression, task.Variables)
+	if err != nil {
+		return nil, err
+	}
+	tree = expression.Balance(tree, times)
+
+	graph := &TaskGraph{
+		TaskID:      task.ID,
+		Expression:  task.Expression,
+		NumericMode: task.NumericMode,
+		Precision:   task.Precision,
+		Operations:  make([]*OperationJob, 0),
+	}
+
+	if tree.IsLeaf() {
+		graph.Value, err = tree.Number(task.NumericMode)
+		return graph, err
+	}
+
+	var build func(node *expression.Node, parent *OperationJob, index int) (*OperationJob, error)
+	build = func(node *expression.Node, parent *OperationJob, index int) (*OperationJob, error) {
+		operands := node.Operands()
+		job := &OperationJob{
+			ID:        len(graph.Operations) + 1,
+			Operation: node.Operation,
+			Args:      make([]expression.Number, len(operands)),
+			Status:    OperationWaiting,
+			parent:    parent,
+			index:     index,
+		}
+		graph.Operations = append(graph.Operations, job)
+
+		for i, operand := range operands {
+			var err error
+			if operand.IsLeaf() {
+				if job.Args[i], err = operand.Number(task.NumericMode); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			job.pending += 1
+			if _, err = build(operand, job, i); err != nil {
+				return nil, err
+			}
+		}
+
+		if job.pending == 0 {
+			job.Status = OperationReady
+		}
+		return job, nil
+	}
+	graph.Root, err = build(tree, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+/*
+Decimal возвращает десятичную запись результата задачи
+в точном режиме вычисления, в остальных режимах пустую строку
+*/
+func (graph *TaskGraph) Decimal(value expression.Number) string {
+	if graph.NumericMode != expression.RationalMode {
+		return ""
+	}
+	return value.Decimal(graph.Precision)
+}
+
+/*
+IsDone возвращает true, если граф полностью посчитан
+*/
+func (graph *TaskGraph) IsDone() bool {
+	return graph.Root == nil || graph.Root.Status == OperationDone
+}
+
+/*
+NextReady возвращает операцию, готовую к выдаче вычислителю,
+или nil, если таких операций сейчас нет
+*/
+func (graph *TaskGraph) NextReady() *OperationJob {
+	for _, job := range graph.Operations {
+		if job.Status == OperationReady {
+			return job
+		}
+	}
+	return nil
+}
+
+/*
+Operation возвращает операцию графа по ее номеру
+*/
+func (graph *TaskGraph) Operation(id int) *OperationJob {
+	if id < 1 || id > len(graph.Operations) {
+		return nil
+	}
+	return graph.Operations[id-1]
+}
+
+/*
+Dispatch отмечает операцию как отданную вычислителю
+*/
+func (graph *TaskGraph) Dispatch(job *OperationJob, solverName string) {
+	job.Status = OperationDispatched
+	job.SolverName = solverName
+}
+
+/*
+Release возвращает операцию, которую считал пропавший
+вычислитель, в число готовых к выдаче
+*/
+func (graph *TaskGraph) Release(job *OperationJob) {
+	if job.Status == OperationDispatched {
+		job.Status = OperationReady
+		job.SolverName = ""
+	}
+}
+
+/*
+SetResult записывает результат операции и передает его
+операции-родителю. Если родитель получил все аргументы,
+он становится готовым к выдаче. Если посчитан корень графа,
+результат записывается в Value
+
+Parameters:
+
+	*OperationJob: Посчитанная операция
+	expression.Number: Результат операции
+*/
+func (graph *TaskGraph) SetResult(job *OperationJob, value expression.Number) {
+	job.Status = OperationDone
+
+	parent := job.parent
+	if parent == nil {
+		graph.Value = value
+		return
+	}
+
+	parent.Args[job.index] = value
+	parent.pending -= 1
+	if parent.pending == 0 {
+		parent.Status = OperationReady
+	}
+}
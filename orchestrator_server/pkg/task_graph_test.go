@@ -0,0 +1,160 @@
+package pkg
+
+import (
+	"expression"
+	"strings"
+	"testing"
+)
+
+func zeroTimes() map[string]int {
+	times := make(map[string]int)
+	for _, name := range expression.Operations() {
+		times[name] = 0
+	}
+	return times
+}
+
+/*
+solveJob считает операцию графа так же, как вычислитель,
+аргументы берутся в скобки, что бы дроби не меняли порядок операций
+*/
+func solveJob(t *testing.T, job *OperationJob, mode string) expression.Number {
+	args := make([]string, 0, len(job.Args))
+	for _, arg := range job.Args {
+		args = append(args, "("+arg.String()+")")
+	}
+
+	text := strings.Join(args, job.Operation)
+	if _, ok := expression.LookupFunction(job.Operation); ok {
+		text = job.Operation + "(" + strings.Join(args, ",") + ")"
+	}
+
+	res, err := expression.Evaluate(text, nil, zeroTimes(), mode)
+	if err != nil {
+		t.Fatalf("Evaluate(%q) returned %v", text, err)
+	}
+	return res
+}
+
+func TestTaskGraph(t *testing.T) {
+	tests := []struct {
+		expression string
+		variables  map[string]float64
+		mode       string
+		operations int
+		result     string
+	}{
+		{"42", nil, expression.FloatMode, 0, "42"},
+		{"1/3", nil, expression.RationalMode, 1, "1/3"},
+		{"2+2*2", nil, expression.FloatMode, 2, "6"},
+		{"(1+2)*(3+4)", nil, expression.FloatMode, 3, "21"},
+		{"1+2+3+4", nil, expression.FloatMode, 3, "10"},
+		{"1/3+1/3+1/3", nil, expression.RationalMode, 5, "1"},
+		{"2^3^2", nil, expression.FloatMode, 2, "512"},
+		{"max(1,2,3)+sqrt(16)", nil, expression.FloatMode, 3, "7"},
+		{"x*x+rate_2", map[string]float64{"x": 2, "rate_2": 0.5}, expression.RationalMode, 2, "9/2"},
+	}
+
+	for _, test := range tests {
+		task := TaskJSON{ID: 1, Expression: test.expression, Variables: test.variables, NumericMode: test.mode}
+		graph, err := NewTaskGraph(task, zeroTimes())
+		if err != nil {
+			t.Errorf("NewTaskGraph(%q) returned %v", test.expression, err)
+			continue
+		}
+		if len(graph.Operations) != test.operations {
+			t.Errorf("NewTaskGraph(%q) has %v operations, want %v", test.expression, len(graph.Operations), test.operations)
+		}
+
+		// Выдаем готовые операции по одной, пока граф не посчитан
+		for steps := 0; !graph.IsDone(); steps++ {
+			if steps > len(graph.Operations) {
+				t.Fatalf("Graph of %q is not done after %v steps", test.expression, steps)
+			}
+			job := graph.NextReady()
+			if job == nil {
+				t.Fatalf("Graph of %q has no ready operation but is not done", test.expression)
+			}
+			if graph.Operation(job.ID) != job {
+				t.Errorf("Operation(%v) of %q is not the ready operation", job.ID, test.expression)
+			}
+
+			graph.Dispatch(job, "solver")
+			if job.Status != OperationDispatched || job.SolverName != "solver" {
+				t.Errorf("Dispatch(%v) of %q = %v, %q", job, test.expression, job.Status, job.SolverName)
+			}
+			if graph.NextReady() == job {
+				t.Errorf("NextReady() of %q returned dispatched operation %v", test.expression, job)
+			}
+			graph.SetResult(job, solveJob(t, job, test.mode))
+		}
+
+		if graph.Value.String() != test.result {
+			t.Errorf("Graph of %q = %v, want %v", test.expression, graph.Value.String(), test.result)
+		}
+	}
+}
+
+func TestTaskGraphRelease(t *testing.T) {
+	graph, err := NewTaskGraph(TaskJSON{Expression: "(1+2)*3", NumericMode: expression.FloatMode}, zeroTimes())
+	if err != nil {
+		t.Fatalf("NewTaskGraph returned %v", err)
+	}
+
+	// Операцию пропавшего вычислителя снова можно выдать
+	job := graph.NextReady()
+	graph.Dispatch(job, "lost")
+	if next := graph.NextReady(); next != nil {
+		t.Fatalf("NextReady() = %v, want nil while %v is dispatched", next, job)
+	}
+	graph.Release(job)
+	if graph.NextReady() != job || job.Status != OperationReady || job.SolverName != "" {
+		t.Fatalf("Release(%v) = %v, %q, want ready operation", job, job.Status, job.SolverName)
+	}
+
+	// Посчитанная операция не возвращается в готовые
+	graph.Dispatch(job, "solver")
+	graph.SetResult(job, expression.Number{Float: 3})
+	graph.Release(job)
+	if job.Status != OperationDone {
+		t.Errorf("Release of done operation changed status to %v", job.Status)
+	}
+	if root := graph.NextReady(); root != graph.Root || root.String() != "3*3" {
+		t.Errorf("NextReady() = %v, want root 3*3", root)
+	}
+
+	tests := []struct {
+		id    int
+		found bool
+	}{
+		{0, false},
+		{1, true},
+		{2, true},
+		{3, false},
+	}
+	for _, test := range tests {
+		if job := graph.Operation(test.id); (job != nil) != test.found {
+			t.Errorf("Operation(%v) = %v, want found %v", test.id, job, test.found)
+		}
+	}
+}
+
+func TestTaskGraphErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		variables  map[string]float64
+	}{
+		{"1+", nil},
+		{"(1+2", nil},
+		{"y+1", nil},
+		{"x+1", map[string]float64{"y": 1}},
+		{"foo(1)", nil},
+	}
+
+	for _, test := range tests {
+		task := TaskJSON{Expression: test.expression, Variables: test.variables, NumericMode: expression.FloatMode}
+		if graph, err := NewTaskGraph(task, zeroTimes()); err == nil {
+			t.Errorf("NewTaskGraph(%q) = %v, want error", test.expression, graph)
+		}
+	}
+}
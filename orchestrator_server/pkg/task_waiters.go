@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"sync"
+)
+
+/*
+TaskWaiters хранит подписки клиентов, которые ждут завершения
+задач. Ключ словаря номер задачи, значение каналы ожидающих
+клиентов. У подписок свой мутекс, потому что о завершении задачи
+сообщают в том числе под мутексом менеджера
+*/
+type TaskWaiters struct {
+	mutex   sync.Mutex
+	waiters map[int]map[chan struct{}]bool
+}
+
+/*
+NewTaskWaiters возвращает ссылку на пустой список подписок
+*/
+func NewTaskWaiters() *TaskWaiters {
+	return &TaskWaiters{
+		waiters: make(map[int]map[chan struct{}]bool),
+	}
+}
+
+/*
+Subscribe подписывает клиента на завершение задачи. Канал закрывается,
+когда задача завершится. Функцию отписки нужно вызвать, когда
+клиент перестал ждать, иначе подписка останется в словаре
+
+Parameters:
+
+	int: Номер задачи
+
+Returns:
+
+	chan struct{}: Канал, который закроется при завершении задачи
+	func(): Функция отписки
+*/
+func (t *TaskWaiters) Subscribe(id int) (chan struct{}, func()) {
+	done := make(chan struct{})
+
+	t.mutex.Lock()
+	if t.waiters[id] == nil {
+		t.waiters[id] = make(map[chan struct{}]bool)
+	}
+	t.waiters[id][done] = true
+	t.mutex.Unlock()
+
+	unsubscribe := func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		delete(t.waiters[id], done)
+		if len(t.waiters[id]) == 0 {
+			delete(t.waiters, id)
+		}
+	}
+	return done, unsubscribe
+}
+
+/*
+Notify сообщает всем подписчикам о завершении задачи
+и удаляет их подписки
+*/
+func (t *TaskWaiters) Notify(id int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for done := range t.waiters[id] {
+		close(done)
+	}
+	delete(t.waiters, id)
+}
+
+/*
+TaskSignal будит вычислители, которые ждут новую задачу.
+Канал из Wait закрывается при следующем вызове Broadcast,
+после чего ожидающие снова ищут задачу для себя
+*/
+type TaskSignal struct {
+	mutex sync.Mutex
+	ready chan struct{}
+}
+
+/*
+NewTaskSignal возвращает ссылку на новый сигнал
+*/
+func NewTaskSignal() *TaskSignal {
+	return &TaskSignal{
+		ready: make(chan struct{}),
+	}
+}
+
+/*
+Wait возвращает канал, который закроется,
+когда появится новая задача или операция
+*/
+func (t *TaskSignal) Wait() chan struct{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.ready
+}
+
+/*
+Broadcast будит всех ожидающих новую задачу
+*/
+func (t *TaskSignal) Broadcast() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	close(t.ready)
+	t.ready = make(chan struct{})
+}
@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"testing"
+)
+
+/*
+isClosed возвращает true, если канал уже закрыт
+*/
+func isClosed(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestTaskWaiters(t *testing.T) {
+	waiters := NewTaskWaiters()
+	first, _ := waiters.Subscribe(1)
+	second, _ := waiters.Subscribe(1)
+	other, unsubscribeOther := waiters.Subscribe(2)
+	left, unsubscribeLeft := waiters.Subscribe(3)
+	unsubscribeLeft()
+
+	waiters.Notify(1)
+	tests := []struct {
+		name   string
+		done   chan struct{}
+		closed bool
+	}{
+		{"first waiter of task 1", first, true},
+		{"second waiter of task 1", second, true},
+		{"waiter of task 2", other, false},
+		{"unsubscribed waiter of task 3", left, false},
+	}
+	for _, test := range tests {
+		if isClosed(test.done) != test.closed {
+			t.Errorf("After Notify(1) %v closed = %v, want %v", test.name, !test.closed, test.closed)
+		}
+	}
+
+	// Повторное уведомление и отписка после него не паникуют,
+	// а пустые подписки удаляются из словаря
+	waiters.Notify(1)
+	waiters.Notify(3)
+	unsubscribeOther()
+	if len(waiters.waiters) != 0 {
+		t.Errorf("Waiters left after notify and unsubscribe: %v", waiters.waiters)
+	}
+}
+
+func TestTaskSignal(t *testing.T) {
+	signal := NewTaskSignal()
+	before := signal.Wait()
+	if isClosed(before) {
+		t.Fatalf("Wait() returned closed channel before Broadcast")
+	}
+	if signal.Wait() != before {
+		t.Errorf("Wait() returned different channels before Broadcast")
+	}
+
+	signal.Broadcast()
+	after := signal.Wait()
+	if !isClosed(before) {
+		t.Errorf("Broadcast did not close waiting channel")
+	}
+	if isClosed(after) {
+		t.Errorf("Wait() after Broadcast returned closed channel")
+	}
+
+	signal.Broadcast()
+	if !isClosed(after) {
+		t.Errorf("Second Broadcast did not close waiting channel")
+	}
+}
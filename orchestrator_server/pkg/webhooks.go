@@ -0,0 +1,380 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+Настройки отправки результатов задач на адреса клиентов: сколько
+попыток делается, задержка перед второй попыткой (каждая следующая
+вдвое дольше, но не дольше webhookMaxDelay), время ожидания ответа
+клиента и сколько отправок берется из базы данных за раз
+*/
+const (
+	webhookMaxAttempts = 10
+	webhookBaseDelay   = 2 * time.Second
+	webhookMaxDelay    = time.Hour
+	webhookTimeout     = 10 * time.Second
+	webhookBatchSize   = 50
+)
+
+/*
+WebhookSender отправляет результаты завершенных задач на CallbackURL
+клиентов. Отправки хранятся в таблице webhook_table, поэтому после
+перезапуска оркестратора недоставленные результаты отправляются снова.
+Время отправки и тело запроса подписываются HMAC-SHA256 с секретом из
+переменной окружения WEBHOOK_SECRET, подпись передается в заголовке
+X-Webhook-Signature, а время отправки в заголовке X-Webhook-Timestamp.
+Если секрет не задан, то запросы отправляются без подписи.
+Адреса локальной машины и частных сетей запрещены, кроме перечисленных
+в переменной окружения WEBHOOK_ALLOW_HOSTS
+*/
+type WebhookSender struct {
+	db     *DatabaseConnection
+	client *http.Client
+	secret []byte
+	allow  hostAllowlist
+	wake   chan struct{}
+}
+
+/*
+NewWebhookSender возвращает ссылку на отправителя результатов задач
+
+Parameters:
+
+	*DatabaseConnection: Соединение с базой данных
+	string: Секрет для подписи тела запроса
+	string: Разрешенные локальные адреса через запятую: имена
+	хостов, IP адреса или сети, например receiver,127.0.0.1,10.0.0.0/8
+
+Returns:
+
+	*WebhookSender: Отправитель результатов
+*/
+func NewWebhookSender(db *DatabaseConnection, secret string, allowHosts string) *WebhookSender {
+	if secret == "" {
+		log.Println("[INFO]: WEBHOOK_SECRET is not set, webhooks are sent unsigned")
+	}
+
+	s := &WebhookSender{
+		db:     db,
+		secret: []byte(secret),
+		allow:  parseHostAllowlist(allowHosts),
+		wake:   make(chan struct{}, 1),
+	}
+
+	// Адрес клиента проверяется при каждом соединении, в том числе после
+	// перенаправления, потому что имя может указывать на другой адрес,
+	// чем при проверке запроса. Прокси не используется, иначе проверялся
+	// бы адрес прокси, а не клиента
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = s.dial
+	s.client = &http.Client{Timeout: webhookTimeout, Transport: transport}
+	return s
+}
+
+/*
+ValidateCallbackURL проверяет, что адрес для результата задачи
+это абсолютный адрес http или https, который не указывает на
+локальную машину, частную или локальную сеть, если этот адрес
+не разрешен в WEBHOOK_ALLOW_HOSTS
+*/
+func (s *WebhookSender) ValidateCallbackURL(callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("Invalid callbackUrl: " + callbackURL)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if s.allow.allowsHost(host) {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("Callback to local address is not allowed: " + callbackURL)
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return errors.New("Callback to local address is not allowed: " + callbackURL)
+	}
+	return nil
+}
+
+/*
+dial открывает соединение с адресом клиента. С разрешенным
+хостом соединяется без проверки, иначе запрещает соединение
+с адресом, который не прошел бы проверку ValidateCallbackURL
+*/
+func (s *WebhookSender) dial(ctx context.Context, network string, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !s.allow.allowsHost(strings.ToLower(strings.TrimSuffix(host, "."))) {
+		dialer.Control = s.dialPublicOnly
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+/*
+hostAllowlist хранит локальные адреса, на которые разрешено
+отправлять результаты: имена хостов и сети IP адресов
+*/
+type hostAllowlist struct {
+	hosts    map[string]bool
+	networks []*net.IPNet
+}
+
+/*
+parseHostAllowlist разбирает список разрешенных адресов через запятую.
+Одиночный IP адрес записывается как сеть из одного адреса
+*/
+func parseHostAllowlist(value string) hostAllowlist {
+	allow := hostAllowlist{hosts: make(map[string]bool)}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(entry), "."))
+		if entry == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			allow.networks = append(allow.networks, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			allow.networks = append(allow.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		allow.hosts[entry] = true
+	}
+	return allow
+}
+
+/*
+allowsHost проверяет, что имя хоста или IP адрес разрешен
+*/
+func (a hostAllowlist) allowsHost(host string) bool {
+	if a.hosts[host] {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return a.allowsIP(ip)
+	}
+	return false
+}
+
+/*
+allowsIP проверяет, что IP адрес входит в одну из разрешенных сетей
+*/
+func (a hostAllowlist) allowsIP(ip net.IP) bool {
+	for _, network := range a.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+isPublicIP проверяет, что адрес не является адресом локальной
+машины, частной сети, локальным адресом канала или групповым адресом
+*/
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+/*
+dialPublicOnly запрещает соединение с IP адресом, в который
+разрешилось имя хоста, если адрес локальный и не разрешен
+*/
+func (s *WebhookSender) dialPublicOnly(network string, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || (!isPublicIP(ip) && !s.allow.allowsIP(ip)) {
+		return errors.New("Callback to local address is not allowed: " + host)
+	}
+	return nil
+}
+
+/*
+Enqueue записывает в базу данных отправку завершенной задачи
+на ее CallbackURL и будит отправителя. Задачи без адреса пропускаются
+
+Parameters:
+
+	string: Тип события (task.completed, task.failed или task.cancelled)
+	TaskJSON: Завершенная задача
+*/
+func (s *WebhookSender) Enqueue(eventType string, task TaskJSON) {
+	if task.CallbackURL == "" {
+		return
+	}
+
+	payload, err := json.Marshal(WebhookPayloadJSON{Event: eventType, Task: task})
+	if err != nil {
+		log.Println("[ERROR]: Can not encoding webhook to JSON: " + err.Error())
+		return
+	}
+	if _, err = s.db.AddWebhookDelivery(task.ID, task.CallbackURL, string(payload)); err != nil {
+		log.Println("[ERROR]: Can not write webhook to database: " + err.Error())
+		return
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+/*
+Run раз в секунду или сразу после Enqueue отправляет
+все отправки, время попытки которых уже наступило.
+Запускается в отдельной горутине
+*/
+func (s *WebhookSender) Run() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		deliveries, err := s.db.GetDueWebhookDeliveries(time.Now(), webhookBatchSize)
+		if err != nil {
+			log.Println("[ERROR]: Can not read webhooks from database: " + err.Error())
+			continue
+		}
+
+		// Отправки разным клиентам не ждут друг друга, а следующая
+		// пачка берется только после того, как записаны результаты этой
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery WebhookDelivery) {
+				defer wg.Done()
+				s.deliver(delivery)
+			}(delivery)
+		}
+		wg.Wait()
+	}
+}
+
+/*
+deliver делает одну попытку отправки и записывает ее результат.
+Ответ с кодом 2xx считается доставкой, иначе следующая попытка
+назначается с экспоненциально растущей задержкой
+*/
+func (s *WebhookSender) deliver(delivery WebhookDelivery) {
+	delivery.Attempts += 1
+	err := s.post(delivery)
+	if err == nil {
+		delivery.Status = 2
+		delivery.LastError = ""
+		log.Printf("[OK]: Webhook %v for task %v was delivered", delivery.ID, delivery.TaskID)
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = 3
+			log.Printf("[ERROR]: Webhook %v for task %v was not delivered: %v", delivery.ID, delivery.TaskID, err)
+		} else {
+			delivery.NextAttempt = time.Now().Add(webhookDelay(delivery.Attempts))
+			log.Printf("[INFO]: Webhook %v for task %v failed, retry at %v: %v",
+				delivery.ID, delivery.TaskID, delivery.NextAttempt.Format(time.RFC3339), err)
+		}
+	}
+
+	if err = s.db.UpdateWebhookDelivery(delivery); err != nil {
+		log.Println("[ERROR]: Can not update webhook in database: " + err.Error())
+	}
+}
+
+/*
+post отправляет тело отправки на адрес клиента с подписью.
+Время отправки входит в подпись, поэтому получатель может
+отклонить старый запрос, отправленный кем-то повторно
+*/
+func (s *WebhookSender) post(delivery WebhookDelivery) error {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Delivery", strconv.Itoa(delivery.ID))
+	if len(s.secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Webhook-Timestamp", timestamp)
+		req.Header.Set("X-Webhook-Signature", "sha256="+s.Sign(timestamp, []byte(delivery.Payload)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Callback responded with status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+/*
+Sign возвращает подпись HMAC-SHA256 строки "<timestamp>.<тело запроса>"
+в виде hex строки
+
+Parameters:
+
+	string: Время отправки в секундах Unix
+	[]byte: Тело запроса
+
+Returns:
+
+	string: Подпись
+*/
+func (s *WebhookSender) Sign(timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+/*
+webhookDelay возвращает задержку перед следующей попыткой
+после attempts неудачных попыток: 2s, 4s, 8s и так далее
+*/
+func webhookDelay(attempts int) time.Duration {
+	delay := webhookBaseDelay
+	for i := 1; i < attempts && delay < webhookMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxDelay {
+		delay = webhookMaxDelay
+	}
+	return delay
+}
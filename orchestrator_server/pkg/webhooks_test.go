@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret    string
+		timestamp string
+		payload   string
+		signature string
+	}{
+		{"secret", "1700000000", `{"event":"task.completed"}`,
+			"8476087d712b027a668e5e7019c04b9b70e5a33b56bba5e57c8aa39aa44ea5e3"},
+	}
+
+	for _, test := range tests {
+		sender := NewWebhookSender(nil, test.secret, "")
+		signature := sender.Sign(test.timestamp, []byte(test.payload))
+		if signature != test.signature {
+			t.Errorf("Sign(%q, %q) = %v, want %v", test.timestamp, test.payload, signature, test.signature)
+		}
+	}
+
+	// Подпись зависит от времени отправки, секрета и тела запроса
+	sender := NewWebhookSender(nil, "secret", "")
+	base := sender.Sign("1", []byte("{}"))
+	if sender.Sign("2", []byte("{}")) == base {
+		t.Errorf("Sign does not depend on timestamp")
+	}
+	if sender.Sign("1", []byte("[]")) == base {
+		t.Errorf("Sign does not depend on payload")
+	}
+	if NewWebhookSender(nil, "other", "").Sign("1", []byte("{}")) == base {
+		t.Errorf("Sign does not depend on secret")
+	}
+}
+
+func TestWebhookDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{0, 2 * time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{10, 1024 * time.Second},
+		{12, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, test := range tests {
+		if delay := webhookDelay(test.attempts); delay != test.delay {
+			t.Errorf("webhookDelay(%v) = %v, want %v", test.attempts, delay, test.delay)
+		}
+	}
+}
+
+func TestValidateCallbackURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		allowHosts string
+		valid      bool
+	}{
+		{"http://example.com/hook", "", true},
+		{"https://8.8.8.8:8443/hook", "", true},
+		{"ftp://example.com/hook", "", false},
+		{"example.com/hook", "", false},
+		{"http:///hook", "", false},
+		{"http://localhost:8080/hook", "", false},
+		{"http://api.localhost/hook", "", false},
+		{"http://127.0.0.1/hook", "", false},
+		{"http://[::1]/hook", "", false},
+		{"http://[::ffff:127.0.0.1]/hook", "", false},
+		{"http://10.1.2.3/hook", "", false},
+		{"http://192.168.0.1/hook", "", false},
+		{"http://169.254.169.254/latest", "", false},
+		{"http://0.0.0.0/hook", "", false},
+		{"http://localhost:8080/hook", "localhost", true},
+		{"http://LOCALHOST./hook", "localhost", true},
+		{"http://127.0.0.1:9000/hook", "127.0.0.1", true},
+		{"http://127.0.0.2:9000/hook", "127.0.0.1", false},
+		{"http://10.1.2.3/hook", "receiver, 10.0.0.0/8", true},
+		{"http://192.168.0.1/hook", "receiver, 10.0.0.0/8", false},
+	}
+
+	for _, test := range tests {
+		sender := NewWebhookSender(nil, "", test.allowHosts)
+		err := sender.ValidateCallbackURL(test.url)
+		if (err == nil) != test.valid {
+			t.Errorf("ValidateCallbackURL(%q) with allowed %q returned %v, want valid %v",
+				test.url, test.allowHosts, err, test.valid)
+		}
+	}
+}
+
+func TestPostAllowHosts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	delivery := WebhookDelivery{ID: 1, URL: server.URL, Payload: "{}"}
+
+	// Локальный получатель доступен, только если он разрешен
+	if err := NewWebhookSender(nil, "", "").post(delivery); err == nil {
+		t.Errorf("post to %v without allowed hosts returned no error", server.URL)
+	}
+	if err := NewWebhookSender(nil, "", "127.0.0.0/8").post(delivery); err != nil {
+		t.Errorf("post to %v with allowed 127.0.0.0/8 returned %v", server.URL, err)
+	}
+}
@@ -16,7 +16,26 @@ import (
 func main() {
 	time.Sleep(5 * time.Second)
 
-	app := pkg.NewApp("Solver", 3)
+	// Режим работы вычислителей задается переменной окружения SOLVER_MODE,
+	// по умолчанию вычислители получают от оркестратора выражение целиком,
+	// а в режиме operation по одной операции
+	mode := os.Getenv("SOLVER_MODE")
+	if mode == "" {
+		mode = pkg.ExpressionMode
+	}
+
+	// Протокол общения с оркестратором задается переменной окружения
+	// SOLVER_PROTOCOL: http (по умолчанию) или grpc. Адрес gRPC сервера
+	// оркестратора берется из переменной ORCHESTRATOR_GRPC_ADDR
+	grpcAddress := ""
+	if os.Getenv("SOLVER_PROTOCOL") == "grpc" {
+		grpcAddress = os.Getenv("ORCHESTRATOR_GRPC_ADDR")
+		if grpcAddress == "" {
+			grpcAddress = "orchestrator_server:8083"
+		}
+	}
+
+	app := pkg.NewApp("Solver", 3, mode, grpcAddress)
 	app.AppRun()
 
 	// Создаем канал с сигналом об остановки сервиса
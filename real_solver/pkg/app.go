@@ -8,7 +8,8 @@ import (
 App описывает структуру с вычислителями
 */
 type App struct {
-	Solvers []*Solver
+	Solvers     []*Solver
+	GRPCAddress string
 }
 
 /*
@@ -18,18 +19,21 @@ Parameters:
 
 	string: Шаблон имени для вычислителя
 	int: Количество вычислителей
+	string: Режим работы вычислителей (ExpressionMode или OperationMode)
+	string: Адрес gRPC сервера оркестратора, если пустой, то вычислители работают по HTTP
 
 Returns:
 
 	*App: Указатель на приложение
 */
-func NewApp(name string, n int) *App {
+func NewApp(name string, n int, mode string, grpcAddress string) *App {
 	app := &App{
-		Solvers: make([]*Solver, n),
+		Solvers:     make([]*Solver, n),
+		GRPCAddress: grpcAddress,
 	}
 
 	for i := 0; i < n; i += 1 {
-		app.Solvers[i] = NewSolver(name+" "+strconv.Itoa(i))
+		app.Solvers[i] = NewSolver(name+" "+strconv.Itoa(i), mode)
 	}
 
 	return app
@@ -40,6 +44,10 @@ AppRun запускает все вычислители приложения
 */
 func (app *App) AppRun() {
 	for _, solver := range app.Solvers {
+		if app.GRPCAddress != "" {
+			solver.RunGRPCStream(app.GRPCAddress)
+			continue
+		}
 		solver.RunHandShakeStream()
 		solver.RunSolverStream()
 	}
@@ -0,0 +1,230 @@
+package pkg
+
+import (
+	"context"
+	"expression"
+	"log"
+	"solverapi"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+/*
+RunGRPCStream запускает вычислитель, который общается с оркестратором
+по gRPC вместо JSON запросов. Вычислитель держит один поток,
+в который раз в секунду отправляет рукопожатие, запрашивает задачу,
+отправляет прогресс и результат. Отмену задачи оркестратор
+присылает в тот же поток. Если поток оборвался, то через секунду
+вычислитель подключается снова, пока оркестратор держит за ним задачу
+
+Parameters:
+
+	string: Адрес gRPC сервера оркестратора, например orchestrator_server:8083
+*/
+func (s *Solver) RunGRPCStream(address string) {
+	go func() {
+		// Результат, который не удалось отправить,
+		// отправляется повторно после переподключения
+		var pending *solverapi.Result
+		for {
+			err := s.runGRPC(address, &pending)
+			log.Println("[ERROR]: gRPC stream to orkestrator was closed: " + err.Error())
+			time.Sleep(1 * time.Second)
+		}
+	}()
+}
+
+/*
+runGRPC обслуживает один поток до его обрыва
+*/
+func (s *Solver) runGRPC(address string, pending **solverapi.Result) error {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := solverapi.NewSolverServiceClient(conn).Connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Отправлять в поток можно только из одной горутины
+	// одновременно, а отправляют и рукопожатия, и прогресс
+	var sendMutex sync.Mutex
+	send := func(message *solverapi.SolverMessage) error {
+		sendMutex.Lock()
+		defer sendMutex.Unlock()
+		return stream.Send(message)
+	}
+
+	hello := &solverapi.SolverMessage{
+		Message: &solverapi.SolverMessage_Hello{
+			Hello: &solverapi.Hello{SolverName: s.SolverName, Mode: s.Mode},
+		},
+	}
+	if err = send(hello); err != nil {
+		return err
+	}
+	log.Println("[OK]: Connected to orkestrator by gRPC")
+
+	if *pending != nil {
+		if err = send(resultMessage(*pending)); err != nil {
+			return err
+		}
+		*pending = nil
+		s.Expression = "None"
+	}
+
+	// Регулярные рукопожатия
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		heartbeat := &solverapi.SolverMessage{
+			Message: &solverapi.SolverMessage_Heartbeat{Heartbeat: &solverapi.Heartbeat{}},
+		}
+		for {
+			select {
+			case <-ticker.C:
+				if err := send(heartbeat); err != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Сообщения оркестратора: задачи передаем в основной цикл,
+	// а отмену задачи выполняем сразу, не дожидаясь конца вычисления
+	tasks := make(chan *solverapi.Task, 1)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			message, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if task := message.GetTask(); task != nil {
+				tasks <- task
+			} else if cancelTask := message.GetCancel(); cancelTask != nil {
+				s.cancelTask(int(cancelTask.TaskId))
+			}
+		}
+	}()
+
+	request := &solverapi.SolverMessage{
+		Message: &solverapi.SolverMessage_TaskRequest{TaskRequest: &solverapi.TaskRequest{}},
+	}
+	for {
+		// Сообщаем, что вычислитель свободен, и ждем задачу
+		if err = send(request); err != nil {
+			return err
+		}
+
+		var task *solverapi.Task
+		select {
+		case task = <-tasks:
+		case err = <-errs:
+			return err
+		}
+
+		message := taskFromMessage(task)
+		total := 1
+		if message.OperationID == 0 {
+			total = countOperations(message.Expression)
+		}
+		result := s.solve(message, func(done int) {
+			progress := &solverapi.SolverMessage{
+				Message: &solverapi.SolverMessage_Progress{
+					Progress: &solverapi.Progress{
+						TaskId:          task.TaskId,
+						OperationId:     task.OperationId,
+						DoneOperations:  int32(done),
+						TotalOperations: int32(total),
+					},
+				},
+			}
+			if err := send(progress); err != nil {
+				log.Println("[ERROR]: Can not send progress to orkestrator: " + err.Error())
+			}
+		})
+
+		*pending = &solverapi.Result{
+			TaskId:      int64(result.TaskID),
+			OperationId: int64(result.OperationID),
+			Expression:  result.Expression,
+			Result:      result.Result,
+			Decimal:     result.Decimal,
+			Status:      int32(result.Status),
+		}
+		if err = send(resultMessage(*pending)); err != nil {
+			return err
+		}
+		log.Println("[OK]: Successful sending result")
+		*pending = nil
+		s.Expression = "None"
+	}
+}
+
+/*
+resultMessage заворачивает результат в сообщение потока
+*/
+func resultMessage(result *solverapi.Result) *solverapi.SolverMessage {
+	return &solverapi.SolverMessage{
+		Message: &solverapi.SolverMessage_Result{Result: result},
+	}
+}
+
+/*
+taskFromMessage переводит задачу из потока в задачу для вычислителя
+*/
+func taskFromMessage(task *solverapi.Task) TaskToSendToSolver {
+	times := make(map[string]int, len(task.Times))
+	for key, val := range task.Times {
+		times[key] = int(val)
+	}
+	return TaskToSendToSolver{
+		Expression:  task.Expression,
+		Variables:   task.Variables,
+		Times:       times,
+		NumericMode: task.NumericMode,
+		Precision:   int(task.Precision),
+		TaskID:      int(task.TaskId),
+		OperationID: int(task.OperationId),
+		Args:        task.Args,
+		Operation:   task.Operation,
+		Time:        int(task.Time),
+	}
+}
+
+/*
+countOperations возвращает количество операций в выражении,
+если выражение не разбирается, то возвращается 0
+*/
+func countOperations(expr string) int {
+	root, err := expression.Parse(expr)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	nodes := []*expression.Node{root}
+	for len(nodes) > 0 {
+		node := nodes[len(nodes)-1]
+		nodes = nodes[:len(nodes)-1]
+		if node == nil || node.IsLeaf() {
+			continue
+		}
+		count += 1
+		nodes = append(nodes, node.Operands()...)
+	}
+	return count
+}
@@ -1,7 +1,10 @@
 package pkg
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"expression"
+	"sync"
 	"time"
 
 	//"regexp"
@@ -9,24 +12,30 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
-	"sync"
-)
-
-var (
-	timesMap map[string]int
 )
 
 /*
 TaskToSendToSolver описывает структуру задачи,
 которая будет отправлена вычислителю, если
-он задачу запросит. Включает в себя само выражение
-и словарь со временем выполнения для операций
+он задачу запросит. Включает в себя само выражение,
+значения его переменных и словарь со временем выполнения
+для операций. В режиме операций вместо целого выражения приходит
+одна операция: ее аргументы, знак операции или имя функции
+и время выполнения. Аргументы передаются строкой, что бы в точном режиме
+вычисления можно было передать дробь, например 1/3
 */
 type TaskToSendToSolver struct {
-	Expression string         `json:"expression"`
-	Times      map[string]int `json:"times"`
+	Expression  string             `json:"expression"`
+	Variables   map[string]float64 `json:"variables"`
+	Times       map[string]int     `json:"times"`
+	NumericMode string             `json:"numericMode"`
+	Precision   int                `json:"precision"`
+	TaskID      int                `json:"taskId"`
+	OperationID int                `json:"operationId"`
+	Args        []string           `json:"args"`
+	Operation   string             `json:"operation"`
+	Time        int                `json:"time"`
 }
 
 /*
@@ -35,37 +44,83 @@ ResultFromSolver описывает JSON с результатом
 иметь вычислитель, желающий отправить ответ.
 Включает в себя выражение, ответ и сообщение с
 ошибками, комментарием от вычислителя и т. п.
-используется в исполнителе SetResultOfSolving
+используется в исполнителе SetResultOfSolving.
+Содержит номер задачи, а в режиме операций и номер операции.
+В точном режиме вычисления ответ это дробь, а в Decimal
+лежит ее десятичная запись с заданной точностью
 */
 type ResultFromSolver struct {
-	SolverName string `json:"solverName"`
-	Expression string `json:"expression"`
-	Result     string `json:"result"`
-	Status     int    `json:"status"`
+	SolverName  string `json:"solverName"`
+	Expression  string `json:"expression"`
+	Result      string `json:"result"`
+	Decimal     string `json:"decimal"`
+	Status      int    `json:"status"`
+	TaskID      int    `json:"taskId"`
+	OperationID int    `json:"operationId"`
 }
 
 /*
 SolverRequestJSON описывает JSON запроса вычислителя
 на сервер. Такую структуру должен содержать запрос,
 для регулярного рукопожатия с сервером или для получения
-задачи. Содержит имя вычислителя, который совершает запрос.
+задачи. Содержит имя вычислителя, который совершает запрос,
+и режим его работы.
 */
 type SolverRequestJSON struct {
 	SolverName string `json:"solverName"`
+	Mode       string `json:"mode"`
 }
 
+/*
+HandShakeResponseJSON описывает JSON ответа оркестратора
+на рукопожатие. Если задачу, которую считает вычислитель,
+отменили, то в ответе приходит ее номер
+*/
+type HandShakeResponseJSON struct {
+	CancelTaskID int `json:"cancelTaskId"`
+}
+
+/*
+Статусы ответа вычислителя: задача посчитана,
+при вычислении возникла ошибка, вычисление отменено
+*/
+const (
+	ResultSuccess   = 0
+	ResultError     = 1
+	ResultCancelled = 2
+)
+
+/*
+Режимы работы вычислителя. В режиме ExpressionMode
+вычислитель получает от оркестратора выражение целиком,
+в режиме OperationMode получает по одной операции,
+а выражение по операциям разбивает сам оркестратор
+*/
+const (
+	ExpressionMode = "expression"
+	OperationMode  = "operation"
+)
+
 /*
 Solver описывает вычислитель 
-Содержит имя вычислителя, вычисляемое им в данный 
+Содержит имя вычислителя, режим его работы, вычисляемое им в данный 
 момент выражение и строки запросов для рукопожатия, 
-получения задачи и отправки результата 
+получения задачи, отправки результата, а так же поиска
+и сохранения результатов подвыражений. Для текущей задачи
+хранится ее номер и функция отмены контекста вычисления
  */
 type Solver struct {
-	HandShakeURL  string
-	GetTaskURL    string
-	SendResultURL string
-	SolverName    string
-	Expression    string
+	HandShakeURL       string
+	GetTaskURL         string
+	SendResultURL      string
+	LookupSubresultURL string
+	StoreSubresultURL  string
+	SolverName         string
+	Mode               string
+	Expression         string
+	taskID             int
+	cancel             context.CancelFunc
+	mutex              sync.Mutex
 }
 
 /*
@@ -74,18 +129,22 @@ NewMessageManager создает новый вычислитель
 Parameters:
 
 	string: Имя вычислителя
+	string: Режим работы (ExpressionMode или OperationMode)
 
 Returns:
 
 	*Solver: Указатель на вычислитель
  */
-func NewSolver(name string) *Solver {
+func NewSolver(name string, mode string) *Solver {
 	return &Solver{
-		HandShakeURL:  "http://orchestrator_server:8082/solverHandShake",
-		GetTaskURL:    "http://orchestrator_server:8082/getTaskToSolving",
-		SendResultURL: "http://orchestrator_server:8082/setResultOfExpression",
-		SolverName:    name,
-		Expression:    "",
+		HandShakeURL:       "http://orchestrator_server:8082/solverHandShake",
+		GetTaskURL:         "http://orchestrator_server:8082/getTaskToSolving",
+		SendResultURL:      "http://orchestrator_server:8082/setResultOfExpression",
+		LookupSubresultURL: "http://orchestrator_server:8082/lookupSubresult",
+		StoreSubresultURL:  "http://orchestrator_server:8082/storeSubresult",
+		SolverName:         name,
+		Mode:               mode,
+		Expression:         "",
 	}
 }
 
@@ -113,8 +172,16 @@ func (s *Solver) RunHandShakeStream() {
 				req, err := http.Post(s.HandShakeURL, "application/json", jsonBytes)
 				if err != nil {
 					log.Println("[ERROR]: Can not connect to orkestrator: " + err.Error())
-				} else {
-					log.Println("[OK]: Hand shake!" + req.Status)
+					continue
+				}
+				log.Println("[OK]: Hand shake!" + req.Status)
+
+				// Если задачу отменили, то прерываем ее вычисление
+				var response HandShakeResponseJSON
+				err = json.NewDecoder(req.Body).Decode(&response)
+				req.Body.Close()
+				if err == nil && response.CancelTaskID != 0 {
+					s.cancelTask(response.CancelTaskID)
 				}
 			}
 		}
@@ -127,6 +194,7 @@ func (s *Solver) RunSolverStream() {
 			// Создаем JSON запроса
 			request := SolverRequestJSON{
 				SolverName: s.SolverName,
+				Mode:       s.Mode,
 			}
 
 			// Формируем JSON
@@ -138,13 +206,22 @@ func (s *Solver) RunSolverStream() {
 			// Переменная для отклика
 			var resp *http.Response
 			for {
-				// Пробуем отправить запрос на получение задачи
+				// Пробуем отправить запрос на получение задачи. Оркестратор
+				// держит запрос, пока задача не появится, поэтому задача
+				// приходит сразу после того, как ее добавили
 				resp, err = http.Post(s.GetTaskURL, "application/json", bytes.NewBuffer(jsonRequest))
+				if err == nil && resp.StatusCode == http.StatusNoContent {
+					// Задача за время ожидания не появилась, спрашиваем снова
+					resp.Body.Close()
+					continue
+				}
 				if err != nil || resp.StatusCode != http.StatusOK {
-					// Если не удалочь отправить успешный запрос или отказано
-					// в получении задачи то ждем две секунды, 
-					// и пытаемся отправить запрос повторно
+					// Если не удалочь отправить успешный запрос,
+					// то ждем две секунды, и пытаемся отправить запрос повторно
 					log.Println("[ERROR]: Can not connect to orkestrator")
+					if err == nil {
+						resp.Body.Close()
+					}
 					time.Sleep(2 * time.Second)
 
 				} else {
@@ -164,28 +241,8 @@ func (s *Solver) RunSolverStream() {
 				panic(err)
 			}
 
-			// Запоминаем выражение которое нужно вычислить и время выполнения операций
-			s.Expression = message.Expression
-			timesMap = message.Times
-
-			// Парсим и вычисляем выражение
-			result := ResultFromSolver{
-				SolverName: s.SolverName,
-				Expression: message.Expression,
-				Result:     "",
-				Status:     0,
-			}
-
-			// Получаем результат, и проверяем канал с ошибками
-			res, err := Solving(message.Expression)
-			if err != nil {
-				result.Status = 1
-				result.Result = err.Error()
-				log.Printf("[INFO]: Can not solving expression")
-			} else {
-				result.Result = fmt.Sprintf("%v", res)
-				log.Printf("[OK]: Solving expression was successful")
-			}
+			// Считаем задачу
+			result := s.solve(message, nil)
 
 			// Формируем JSON
 			jsonResult, err := json.Marshal(result)
@@ -213,193 +270,207 @@ func (s *Solver) RunSolverStream() {
 	}()
 }
 
-func Solving(expression string) (float64, error) {
-	// Создаем синхронизатор
-	wg := sync.WaitGroup{}
+/*
+solve считает задачу или одну операцию графа задачи и возвращает
+результат для оркестратора. Пока задача считается, ее можно
+отменить через cancelTask. Функция progress, если она задана,
+вызывается после каждой посчитанной операции
 
-	// Создаем канал с ошибками, при передачи ошибки, нужно прервать выполнение вычисления
-	errChan := make(chan error, 1)
+Parameters:
 
-	// Создаем массив чисел в виде строк
-	stringArrayOfNumber := strings.FieldsFunc(expression, func(r rune) bool {
-		return r == '+' || r == '-' || r == '/' || r == '*'
-	})
+	TaskToSendToSolver: Задача от оркестратора
+	func(int): Функция, получающая количество посчитанных операций
 
-	// Преобразуем числа из строкового представления в числовое
-	arrayOfNumber := make([]float64, 0)
-	for _, val := range stringArrayOfNumber {
-		f, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			return 0.0, err
-		}
-		arrayOfNumber = append(arrayOfNumber, f)
-	}
+Returns:
 
-	// Создаем массив операций
-	arrayOfOperation := make([]string, 0)
-	for _, ch := range strings.Split(expression, "") {
-		if ch == "+" || ch == "-" || ch == "/" || ch == "*" {
-			arrayOfOperation = append(arrayOfOperation, ch)
-		}
+	ResultFromSolver: Результат для оркестратора
+*/
+func (s *Solver) solve(message TaskToSendToSolver, progress func(int)) ResultFromSolver {
+	// Запоминаем выражение которое нужно вычислить
+	s.Expression = message.Expression
+
+	// Создаем контекст вычисления, который отменяется,
+	// если оркестратор сообщит об отмене задачи
+	ctx := s.startTask(message.TaskID)
+
+	// Парсим и вычисляем выражение
+	result := ResultFromSolver{
+		SolverName:  s.SolverName,
+		Expression:  message.Expression,
+		Result:      "",
+		Status:      ResultSuccess,
+		TaskID:      message.TaskID,
+		OperationID: message.OperationID,
 	}
 
-	// Группа операций первого приоритета, пример:
-	// В выражении 0+1*2+3+4-5*6/7 -> 1*2 и 5*6/7 являются группами
-	// в этом выражении 1*2 и 5*6/7 будут сначала выполнены в отдельных потоках
-	// и их результаты будут положены в: 0+_+3+4-_
-	// после чего будет найдено финальное значение выражения
-
-	// Счетчик количества групп состоящийх из операций второго приоритета
-	groupCounter := 1
-	for i := 0; i < len(arrayOfOperation); i += 1 {
-		// Ищем операции второго приоритета
-		if arrayOfOperation[i] == "+" || arrayOfOperation[i] == "-" {
-			groupCounter += 1
+	// Получаем результат, и проверяем канал с ошибками.
+	// Если пришла одна операция, то считаем только ее.
+	// Каждую операцию сначала ищем в хранилище подвыражений
+	var res expression.Number
+	var err error
+	if message.OperationID != 0 {
+		s.Expression = operationString(message)
+		times := map[string]int{message.Operation: message.Time}
+		res, err = SolvingOperation(message, expression.WithContext(ctx,
+			progressCalculator(s.MemoCalculator(expression.TimedCalculator(times), message.NumericMode), progress)))
+	} else {
+		res, err = Solving(message.Expression, message.Variables, message.Times, message.NumericMode,
+			expression.WithContext(ctx, progressCalculator(
+				s.MemoCalculator(expression.TimedCalculator(message.Times), message.NumericMode), progress)))
+	}
+	s.finishTask()
+	if errors.Is(err, context.Canceled) {
+		result.Status = ResultCancelled
+		result.Result = "cancelled"
+		log.Printf("[INFO]: Solving expression was cancelled")
+	} else if err != nil {
+		result.Status = ResultError
+		result.Result = err.Error()
+		log.Printf("[INFO]: Can not solving expression")
+	} else {
+		result.Result = res.String()
+		if message.NumericMode == expression.RationalMode {
+			result.Decimal = res.Decimal(message.Precision)
 		}
+		log.Printf("[OK]: Solving expression was successful")
 	}
+	return result
+}
 
-	counter := 0
-	operations := make([]string, 0) // Список операций группы первого приоритета
-	numbers := make([]float64, 0)   // Список чисел группы первого приоритета
-	begin := false                  // Нашли начало группы первого приоритета
-
-	// Массив в который будут отправлены числа,
-	// над которыми будут выполняться операции второго приоритета
-	groupResultArray := make([]float64, groupCounter)
-	// Массив с операциями второго приоритета
-	groupOperatinArray := make([]string, groupCounter-1)
-	for i := 0; i < len(arrayOfOperation); i += 1 {
-		fmt.Printf(" --- Итерация: %v --- \n", i)
-		// Если нашли операцию второго приоритета, значит
-		// записываем число с операцией в массив, либо, если мы до этого нашли
-		// группу первого приоритета, ее надо вычислить и отправить ее
-		// результат в массив вместо числа
-		if arrayOfOperation[i] == "+" || arrayOfOperation[i] == "-" {
-			if begin {
-				// Если мы нашли операцию второго приоритета,
-				// а до этого была операция первого приоритета,
-				// то записываем в число в список группы первого приоритета
-				numbers = append(numbers, arrayOfNumber[i])
-				// Записываем операцию второго приоритета в массив
-				groupOperatinArray[counter] = arrayOfOperation[i]
-				// запускаем вычисление группы в отдельной горутине,
-				// передав ей массивы со значениями и операциями,
-				// а так же индекс, куда надо положить результат вычисления группы
-				wg.Add(1)
-				go func(numbers []float64, operations []string, counter int) {
-					defer wg.Done()
-					x, err := FirstPriority(numbers, operations)
-					if err != nil {
-						errChan <- err
-					}
-					groupResultArray[counter] = x
-				}(numbers, operations, counter)
-
-				// Очищаем массивы для поиска следующей группы операций первого приоритета
-				operations = make([]string, 0)
-				numbers = make([]float64, 0)
-				begin = false
-				counter += 1
-			} else {
-				// Если найдена операция второго приоритета,
-				// а до этого не быт открыт набор в группу первого приоритета,
-				// то просто записываем текущий знак и текущее число
-				groupResultArray[counter] = arrayOfNumber[i]
-				groupOperatinArray[counter] = arrayOfOperation[i]
-				counter += 1
-			}
+/*
+progressCalculator оборачивает функцию, выполняющую операцию, так,
+что после каждой посчитанной операции вызывается функция progress
+с количеством уже посчитанных операций. Операции выражения
+считаются параллельно, поэтому счетчик защищен мутексом
+*/
+func progressCalculator(calculate expression.Calculator, progress func(int)) expression.Calculator {
+	if progress == nil {
+		return calculate
+	}
 
-			// Если мы на конце массива с операциями, надо доподнительно записать
-			// крайнее в выражении число
-			if i == len(arrayOfOperation)-1 {
-				groupResultArray[counter] = arrayOfNumber[i+1]
-			}
+	var mutex sync.Mutex
+	done := 0
+	return func(operation string, args []expression.Number) (expression.Number, error) {
+		res, err := calculate(operation, args)
+		if err != nil {
+			return res, err
 		}
 
-		// Если нашли операцию первого приоритета, создаем
-		// группу первого приоритета, содержащую только операции первого порядка
-		// Такая группа выполняется в отдельной горутине, однако внутри себя
-		// группа может распраралелиться еще
-		if arrayOfOperation[i] == "/" || arrayOfOperation[i] == "*" {
-			if !begin {
-				begin = true
-			}
-			// Добавляем число, после которого идет оператор первого приоритета
-			numbers = append(numbers, arrayOfNumber[i])
-			// Добавляем оператор после этого числа
-			operations = append(operations, arrayOfOperation[i])
-
-			// Если мы на конце, то есть выражение заканчивается произведением/делением
-			// то добавляем крайнее число и запускаем подсчет
-			if i == len(arrayOfOperation)-1 {
-				numbers = append(numbers, arrayOfNumber[i+1])
-				wg.Add(1)
-				go func(numbers []float64, operations []string, counter int) {
-					defer wg.Done()
-					x, err := FirstPriority(numbers, operations)
-					if err != nil {
-						errChan <- err
-					}
-					groupResultArray[counter] = x
-				}(numbers, operations, counter)
-			}
-		}
+		mutex.Lock()
+		done += 1
+		progress(done)
+		mutex.Unlock()
+		return res, nil
 	}
+}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+/*
+startTask запоминает номер задачи, которую начинает считать
+вычислитель, и возвращает контекст ее вычисления
+*/
+func (s *Solver) startTask(taskID int) context.Context {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.taskID = taskID
+	s.cancel = cancel
+	return ctx
+}
+
+/*
+finishTask освобождает контекст посчитанной задачи
+*/
+func (s *Solver) finishTask() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	// Отлавливаем ошибки, возникающие в процессе вычислений
-	for i := range errChan {
-		log.Printf("Error %v", i)
-		return 0.0, i
+	if s.cancel != nil {
+		s.cancel()
 	}
+	s.taskID = 0
+	s.cancel = nil
+}
 
-	// Ждем пока посчитаются все операции первого приоритета
-	// после чего получается массив чисел, над которыми остается
-	// совершать только сложения и вычитания, то есть операции второго приоритета
-	//wg.Wait()
-	return SecondPriority(groupResultArray, groupOperatinArray), nil
+/*
+cancelTask отменяет вычисление задачи, если вычислитель
+все еще считает задачу с этим номером
+*/
+func (s *Solver) cancelTask(taskID int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.taskID == taskID && s.cancel != nil {
+		log.Printf("[INFO]: Task %v was cancelled by orchestrator", taskID)
+		s.cancel()
+	}
 }
 
 /*
-FirstPriority
+Solving разбирает выражение в дерево, подставляет в него
+значения переменных и вычисляет его. Цепочки ассоциативных операций перестраиваются в сбалансированные
+поддеревья, после чего планировщик запускает каждую операцию,
+как только готовы ее операнды. Например в выражении 1+2+3+4
+сложения 1+2 и 3+4 будут выполняться одновременно
+
+Parameters:
+
+	string: Входное выражение в строке
+	map[string]float64: Значения переменных выражения
+	map[string]int: Время выполнения операций задачи в секундах
+	string: Режим вычисления (FloatMode или RationalMode)
+	expression.Calculator: Функция, выполняющая одну операцию
+
+Returns:
+
+	expression.Number: Результат вычисления
+	error: Ошибки разбора и вычисления
 */
-func FirstPriority(arrayOfNumber []float64, arrayOfOperation []string) (float64, error) {
-	res := arrayOfNumber[0]
-	for i := 0; i < len(arrayOfOperation); i += 1 {
-		switch arrayOfOperation[i] {
-		case "*":
-			res *= arrayOfNumber[i+1]
-			time.Sleep(time.Duration(timesMap["*"]) * time.Second)
-		case "/":
-			if arrayOfNumber[i+1] != 0.0 {
-				res /= arrayOfNumber[i+1]
-				time.Sleep(time.Duration(timesMap["/"]) * time.Second)
-			} else {
-				return 0.0, fmt.Errorf("Division by zero: %v / %v", res, arrayOfNumber[i+1])
-			}
-		}
+func Solving(expr string, variables map[string]float64, times map[string]int, mode string,
+	calculate expression.Calculator) (expression.Number, error) {
+	res, err := expression.EvaluateWith(expr, variables, times, mode, calculate)
+	if err != nil {
+		log.Printf("Error %v", err)
+		return expression.Number{}, err
 	}
+
 	return res, nil
 }
 
 /*
-SecondPriority
+operationString возвращает операцию в виде строки, например 3*4 или pow(2,3)
 */
-func SecondPriority(arrayOfNumber []float64, arrayOfOperation []string) float64 {
-	res := arrayOfNumber[0]
-	for i := 0; i < len(arrayOfOperation); i += 1 {
-		switch arrayOfOperation[i] {
-		case "+":
-			res += arrayOfNumber[i+1]
-			time.Sleep(time.Duration(timesMap["+"]) * time.Second)
-		case "-":
-			res -= arrayOfNumber[i+1]
-			time.Sleep(time.Duration(timesMap["-"]) * time.Second)
+func operationString(task TaskToSendToSolver) string {
+	if _, ok := expression.LookupFunction(task.Operation); ok || len(task.Args) != 2 {
+		return task.Operation + "(" + strings.Join(task.Args, ",") + ")"
+	}
+	return task.Args[0] + task.Operation + task.Args[1]
+}
+
+/*
+SolvingOperation вычисляет одну операцию, которую
+оркестратор выделил из графа задачи, функцией calculate,
+которая выдерживает время выполнения, пришедшее вместе с операцией
+
+Parameters:
+
+	TaskToSendToSolver: Задача с одной операцией
+	expression.Calculator: Функция, выполняющая операцию
+
+Returns:
+
+	expression.Number: Результат операции
+	error: Ошибки вычисления
+*/
+func SolvingOperation(task TaskToSendToSolver, calculate expression.Calculator) (expression.Number, error) {
+	args := make([]expression.Number, 0, len(task.Args))
+	for _, text := range task.Args {
+		arg, err := expression.ParseNumber(text, task.NumericMode)
+		if err != nil {
+			return expression.Number{}, err
 		}
+		args = append(args, arg)
 	}
-	return res
+
+	return calculate(task.Operation, args)
 }
@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"expression"
+	"log"
+	"net/http"
+	"time"
+)
+
+/*
+SubresultJSON описывает JSON с результатом подвыражения
+в общем хранилище оркестратора. Found выставляется
+в ответе, если результат по ключу найден
+*/
+type SubresultJSON struct {
+	Key    string `json:"key"`
+	Result string `json:"result"`
+	Found  bool   `json:"found"`
+}
+
+/*
+subresultClient используется для запросов к хранилищу
+подвыражений. Если оркестратор не отвечает, то вычислитель
+не ждет его долго, а считает операцию сам
+*/
+var subresultClient = &http.Client{Timeout: 2 * time.Second}
+
+/*
+MemoCalculator оборачивает функцию, выполняющую операцию, так,
+что перед выполнением операции ее результат ищется в общем
+хранилище оркестратора, а посчитанный результат туда сохраняется.
+Поэтому одинаковые подвыражения разных задач, например (12*17),
+считаются только один раз
+
+Parameters:
+
+	expression.Calculator: Функция, выполняющая операцию с задержкой
+	string: Режим вычисления (FloatMode или RationalMode)
+
+Returns:
+
+	expression.Calculator: Функция, выполняющая операцию через хранилище
+*/
+func (s *Solver) MemoCalculator(calculate expression.Calculator, mode string) expression.Calculator {
+	return func(operation string, args []expression.Number) (expression.Number, error) {
+		key := expression.OperationKey(operation, args, mode)
+		if res, ok := s.lookupSubresult(key, mode); ok {
+			log.Printf("[INFO]: Subresult %v was found in storage", key)
+			return res, nil
+		}
+
+		res, err := calculate(operation, args)
+		if err != nil {
+			return expression.Number{}, err
+		}
+
+		s.storeSubresult(key, res.String())
+		return res, nil
+	}
+}
+
+/*
+lookupSubresult ищет результат операции в хранилище оркестратора.
+Любая ошибка считается промахом, тогда операция считается заново
+*/
+func (s *Solver) lookupSubresult(key string, mode string) (expression.Number, bool) {
+	jsonRequest, err := json.Marshal(SubresultJSON{Key: key})
+	if err != nil {
+		log.Println("[ERROR]: Can not encoding to JSON: " + err.Error())
+		return expression.Number{}, false
+	}
+
+	resp, err := subresultClient.Post(s.LookupSubresultURL, "application/json", bytes.NewBuffer(jsonRequest))
+	if err != nil {
+		log.Println("[ERROR]: Can not lookup subresult: " + err.Error())
+		return expression.Number{}, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return expression.Number{}, false
+	}
+
+	var message SubresultJSON
+	if err = json.NewDecoder(resp.Body).Decode(&message); err != nil || !message.Found {
+		return expression.Number{}, false
+	}
+
+	res, err := expression.ParseNumber(message.Result, mode)
+	if err != nil {
+		log.Println("[ERROR]: Can not parse subresult: " + err.Error())
+		return expression.Number{}, false
+	}
+	return res, true
+}
+
+/*
+storeSubresult сохраняет результат операции в хранилище оркестратора
+*/
+func (s *Solver) storeSubresult(key string, result string) {
+	jsonRequest, err := json.Marshal(SubresultJSON{Key: key, Result: result})
+	if err != nil {
+		log.Println("[ERROR]: Can not encoding to JSON: " + err.Error())
+		return
+	}
+
+	resp, err := subresultClient.Post(s.StoreSubresultURL, "application/json", bytes.NewBuffer(jsonRequest))
+	if err != nil {
+		log.Println("[ERROR]: Can not store subresult: " + err.Error())
+		return
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,8 @@
+/*
+Пакет solverapi содержит gRPC протокол между оркестратором
+и вычислителями. Код в solver.pb.go и solver_grpc.pb.go
+сгенерирован из solver.proto командой go generate
+*/
+package solverapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative solver.proto
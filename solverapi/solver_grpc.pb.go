@@ -0,0 +1,154 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: solver.proto
+
+package solverapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	SolverService_Connect_FullMethodName = "/solverapi.SolverService/Connect"
+)
+
+// SolverServiceClient is the client API for SolverService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SolverService это протокол между оркестратором и вычислителями.
+// Вместо трех JSON эндпоинтов (/getTaskToSolving, /setResultOfExpression
+// и /solverHandShake) вычислитель открывает один двунаправленный поток,
+// по которому идут рукопожатия, выдача задач, прогресс и результаты.
+// Оркестратор сразу узнает о разрыве потока и сам отправляет отмену задачи
+type SolverServiceClient interface {
+	Connect(ctx context.Context, opts ...grpc.CallOption) (SolverService_ConnectClient, error)
+}
+
+type solverServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSolverServiceClient(cc grpc.ClientConnInterface) SolverServiceClient {
+	return &solverServiceClient{cc}
+}
+
+func (c *solverServiceClient) Connect(ctx context.Context, opts ...grpc.CallOption) (SolverService_ConnectClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SolverService_ServiceDesc.Streams[0], SolverService_Connect_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &solverServiceConnectClient{ClientStream: stream}
+	return x, nil
+}
+
+type SolverService_ConnectClient interface {
+	Send(*SolverMessage) error
+	Recv() (*OrchestratorMessage, error)
+	grpc.ClientStream
+}
+
+type solverServiceConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *solverServiceConnectClient) Send(m *SolverMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *solverServiceConnectClient) Recv() (*OrchestratorMessage, error) {
+	m := new(OrchestratorMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SolverServiceServer is the server API for SolverService service.
+// All implementations must embed UnimplementedSolverServiceServer
+// for forward compatibility
+//
+// SolverService это протокол между оркестратором и вычислителями.
+// Вместо трех JSON эндпоинтов (/getTaskToSolving, /setResultOfExpression
+// и /solverHandShake) вычислитель открывает один двунаправленный поток,
+// по которому идут рукопожатия, выдача задач, прогресс и результаты.
+// Оркестратор сразу узнает о разрыве потока и сам отправляет отмену задачи
+type SolverServiceServer interface {
+	Connect(SolverService_ConnectServer) error
+	mustEmbedUnimplementedSolverServiceServer()
+}
+
+// UnimplementedSolverServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSolverServiceServer struct {
+}
+
+func (UnimplementedSolverServiceServer) Connect(SolverService_ConnectServer) error {
+	return status.Errorf(codes.Unimplemented, "method Connect not implemented")
+}
+func (UnimplementedSolverServiceServer) mustEmbedUnimplementedSolverServiceServer() {}
+
+// UnsafeSolverServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SolverServiceServer will
+// result in compilation errors.
+type UnsafeSolverServiceServer interface {
+	mustEmbedUnimplementedSolverServiceServer()
+}
+
+func RegisterSolverServiceServer(s grpc.ServiceRegistrar, srv SolverServiceServer) {
+	s.RegisterService(&SolverService_ServiceDesc, srv)
+}
+
+func _SolverService_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SolverServiceServer).Connect(&solverServiceConnectServer{ServerStream: stream})
+}
+
+type SolverService_ConnectServer interface {
+	Send(*OrchestratorMessage) error
+	Recv() (*SolverMessage, error)
+	grpc.ServerStream
+}
+
+type solverServiceConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *solverServiceConnectServer) Send(m *OrchestratorMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *solverServiceConnectServer) Recv() (*SolverMessage, error) {
+	m := new(SolverMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SolverService_ServiceDesc is the grpc.ServiceDesc for SolverService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SolverService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "solverapi.SolverService",
+	HandlerType: (*SolverServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Connect",
+			Handler:       _SolverService_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "solver.proto",
+}